@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nao1215/mkgoprj/v2/internal/changelog"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Update Changelog.md from git history written in Conventional Commits",
+	Long: `Update Changelog.md from git history written in Conventional Commits.
+mkgoprj reads "$ git log" of the repository that has Changelog.md, and groups the commits
+between tags into Keep a Changelog sections (feat=Added, perf/refactor/revert=Changed,
+fix=Fixed, security=Security). The other commit types (e.g. docs, test, ci, chore) are skipped.
+The sections of the versions already in Changelog.md are kept, so you can edit them by hand.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(updateChangelog(cmd, args))
+	},
+}
+
+func init() {
+	changelogCmd.Flags().String("file", "Changelog.md", "Changelog file to be updated")
+	changelogCmd.Flags().Bool("dry-run", false, "Print the updated changelog to STDOUT without writing the file")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+func updateChangelog(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--file)")
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--dry-run)")
+	}
+
+	current, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	releases, err := changelog.Log(cmd.Context(), filepath.Dir(file))
+	if err != nil {
+		return rep.Error(report.CodeGitCommand, err.Error())
+	}
+
+	updated := changelog.Update(string(current), releases)
+	if dryRun {
+		fmt.Fprint(cmd.OutOrStdout(), updated)
+		return 0
+	}
+	if err := os.WriteFile(file, []byte(updated), 0644); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	rep.Info(fmt.Sprintf("updated %s (%d releases)", file, len(releases)-1))
+	return 0
+}
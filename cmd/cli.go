@@ -3,8 +3,8 @@ package cmd
 import (
 	"os"
 
-	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
 	"github.com/nao1215/mkgoprj/v2/internal/project"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -19,22 +19,31 @@ You need to specify IMPORT_PATH as argument. ※ IMPORT_PATH is same as $ go mod
 }
 
 func init() {
-	cliCmd.Flags().BoolP("no-root", "n", false, "Create files in the current directory without creating the project root directory")
+	addProjectFlags(cliCmd)
+	addDockerFlag(cliCmd)
 	rootCmd.AddCommand(cliCmd)
 }
 
 func cli(cmd *cobra.Command, args []string) int {
 	if len(args) == 0 {
-		ioutils.Die("need import path or project name")
+		newReporter(cmd).Fatal(report.CodeInvalidArgument, "need import path or project name")
 	}
 
-	noRoot, err := cmd.Flags().GetBool("no-root")
+	importPath, noRoot, opt := parseProjectFlags(cmd, args)
+
+	docker, err := cmd.Flags().GetBool("docker")
 	if err != nil {
-		ioutils.Die("can not parse command line argument (--no-root)")
+		opt.Reporter.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--docker)")
 	}
+	opt.Docker = docker
 
-	prj := project.NewProject(args[0], false, true, noRoot)
-	prj.Make()
+	prj := project.NewProject(importPath, false, true, noRoot, opt)
+	makeProject(cmd, prj)
 
 	return 0
 }
+
+// addDockerFlag adds --docker option for the project kinds that build binary.
+func addDockerFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("docker", false, "Generate Dockerfile, .dockerignore, Makefile docker targets and docker build workflow")
+}
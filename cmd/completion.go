@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nao1215/mkgoprj/v2/internal/completion"
+	"github.com/nao1215/mkgoprj/v2/internal/print"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion",
+	Short: "Install, uninstall or print shell completion (" + strings.Join(completion.Shells(), "/") + ")",
+	Long: `Install, uninstall or print shell completion.
+mkgoprj never changes files in your home directory without this subcommand.`,
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:       "install SHELL",
+	Short:     "Install shell completion file and load it from shell rc file",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: completion.Shells(),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(completionInstall(cmd, args))
+	},
+}
+
+var completionUninstallCmd = &cobra.Command{
+	Use:       "uninstall SHELL",
+	Short:     "Remove shell completion file and the setting in shell rc file",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: completion.Shells(),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(completionUninstall(cmd, args))
+	},
+}
+
+var completionPrintCmd = &cobra.Command{
+	Use:       "print SHELL",
+	Short:     "Print shell completion script to STDOUT",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: completion.Shells(),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(completionPrint(cmd, args))
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{completionInstallCmd, completionUninstallCmd} {
+		c.Flags().Bool("dry-run", false, "Only show the files to be changed")
+		c.Flags().BoolP("yes", "y", false, "Change files without confirmation")
+	}
+	completionCmd.AddCommand(completionInstallCmd)
+	completionCmd.AddCommand(completionUninstallCmd)
+	completionCmd.AddCommand(completionPrintCmd)
+	rootCmd.AddCommand(completionCmd)
+}
+
+func completionInstall(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	changes, err := completion.InstallChanges(rootCmd, args[0])
+	if err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	if len(changes) == 0 {
+		rep.Info(args[0] + " completion is already installed")
+		return 0
+	}
+	return applyCompletionChanges(cmd, rep, changes)
+}
+
+func completionUninstall(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	changes, err := completion.UninstallChanges(rootCmd, args[0])
+	if err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	if len(changes) == 0 {
+		rep.Info(args[0] + " completion is not installed")
+		return 0
+	}
+	return applyCompletionChanges(cmd, rep, changes)
+}
+
+func completionPrint(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	if err := completion.Print(rootCmd, args[0], os.Stdout); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	return 0
+}
+
+// applyCompletionChanges shows the file operations and executes them after user consent.
+// The file operations are shown also in quiet mode because the user answers by them.
+func applyCompletionChanges(cmd *cobra.Command, rep report.Reporter, changes []completion.Change) int {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--dry-run)")
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--yes)")
+	}
+
+	for _, c := range changes {
+		fmt.Fprintln(cmd.OutOrStdout(), c.String())
+	}
+	if dryRun {
+		return 0
+	}
+	if !yes && !print.Question("Do you want to change the above files?") {
+		return 0
+	}
+
+	if err := completion.Apply(changes); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	rep.Info("done. To activate the change, restart the shell")
+	return 0
+}
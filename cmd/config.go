@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/nao1215/mkgoprj/v2/internal/config"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get, set or list the default values of options",
+	Long: `Get, set or list the default values of options.
+The user config file is $XDG_CONFIG_HOME/mkgoprj/config.yaml (~/.config/mkgoprj/config.yaml).
+The yaml files in .mkgoprj.d directory of the repository override the user config.
+The key is the option name of cli/library subcommand without "--" (e.g. lint-preset, docker, go),
+and import-prefix is added to the import path without domain (e.g. "foo" -> "github.com/acme/foo").`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the default value of option",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(configGet(cmd, args))
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Set the default value of option to the user config file",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(configSet(cmd, args))
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the default values of options and the files that set them",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(configList(cmd, args))
+	},
+}
+
+func init() {
+	configSetCmd.Flags().Bool("repo", false, "Set the value to "+filepath.Join(config.RepoDirName, "config.yaml")+" in the current directory")
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func configGet(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	cfg, err := config.Load(".")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+	value, ok := cfg.Get(args[0])
+	if !ok {
+		return rep.Error(report.CodeInvalidArgument, "'"+args[0]+"' is not set")
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), value)
+	return 0
+}
+
+func configSet(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	key, value := args[0], args[1]
+	if err := checkConfig(key, value); err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+
+	repo, err := cmd.Flags().GetBool("repo")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--repo)")
+	}
+	file := filepath.Join(config.RepoDirName, "config.yaml")
+	if !repo {
+		if file, err = config.UserFile(); err != nil {
+			return rep.Error(report.CodeIO, err.Error())
+		}
+	}
+
+	values, err := config.ReadFile(file)
+	if err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	values[key] = value
+	if err := config.WriteFile(file, values); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	rep.Info("set " + key + "=" + value + " to " + file)
+	return 0
+}
+
+func configList(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	cfg, err := config.Load(".")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, key := range cfg.Keys() {
+		value, _ := cfg.Get(key)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, value, cfg.Source(key))
+	}
+	if err := w.Flush(); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	return 0
+}
+
+// perProjectOptions are the options that can not be the default value
+// because they are different in each project.
+var perProjectOptions = []string{"dir", "bin-name", "package"}
+
+// checkConfig returns error if key is not the option of cli/library subcommand
+// or value can not be set to the option.
+func checkConfig(key, value string) error {
+	if key == config.ImportPrefixKey {
+		return nil
+	}
+	for _, v := range perProjectOptions {
+		if key == v {
+			return fmt.Errorf("'%s' is different in each project and can not be set to config", key)
+		}
+	}
+
+	// Use new command so that the value is not set to the actual command.
+	c := &cobra.Command{}
+	addProjectFlags(c)
+	addDockerFlag(c)
+	addOutputFlags(c.Flags())
+	flag := c.Flags().Lookup(key)
+	if flag == nil {
+		return fmt.Errorf("unknown key '%s' (see '$ mkgoprj cli --help' for the option names)", key)
+	}
+	if err := flag.Value.Set(value); err != nil {
+		return fmt.Errorf("invalid value '%s' for '%s': %w", value, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nao1215/mkgoprj/v2/internal/gotool"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/nao1215/mkgoprj/v2/internal/target"
+	"github.com/spf13/cobra"
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps [cli|library]",
+	Short: "Show dependencies and versions that the project introduces",
+	Long: `Show go modules and tools (with versions pinned by mkgoprj) that the project introduces.
+If project kind is not specified, show all project kinds. The options that add tools
+(--docker, --lint-preset, --hooks and --devcontainer) are same as cli/library subcommand.`,
+	ValidArgs: []string{"cli", "library"},
+	Args:      cobra.OnlyValidArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(deps(cmd, args))
+	},
+}
+
+func init() {
+	depsCmd.Flags().Bool("docker", false, "Include the dependencies of --docker option")
+	depsCmd.Flags().String("lint-preset", target.LintPresetStandard,
+		"golangci-lint preset for .golangci.yml ("+strings.Join(target.LintPresets(), "/")+")")
+	depsCmd.Flags().String("hooks", target.HooksNone,
+		"Include the dependencies of --hooks option ("+strings.Join(target.HookStyles(), "/")+")")
+	depsCmd.Flags().Bool("devcontainer", false, "Include the dependencies of --devcontainer option")
+	depsCmd.Flags().String("go", "", "Go version (default: $ go env GOVERSION)")
+	rootCmd.AddCommand(depsCmd)
+}
+
+func deps(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	kinds := args
+	if len(kinds) == 0 {
+		kinds = []string{"cli", "library"}
+	}
+
+	docker, err := cmd.Flags().GetBool("docker")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--docker)")
+	}
+	lintPreset, err := cmd.Flags().GetString("lint-preset")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--lint-preset)")
+	}
+	if err := checkLintPreset(lintPreset); err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+	hooks, err := cmd.Flags().GetString("hooks")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--hooks)")
+	}
+	if err := checkHooks(hooks); err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+	devcontainer, err := cmd.Flags().GetBool("devcontainer")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--devcontainer)")
+	}
+	goVersion, err := cmd.Flags().GetString("go")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--go)")
+	}
+	if goVersion == "" {
+		goVersion = gotool.Version()
+	} else if err := checkGoVersion(goVersion); err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for i, kind := range kinds {
+		lib, cli := kind == "library", kind == "cli"
+		opt := target.Option{
+			Docker:       docker,
+			LintPreset:   lintPreset,
+			Hooks:        hooks,
+			Devcontainer: devcontainer,
+			GoVersion:    goVersion,
+		}
+
+		if i != 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%s project]\n", kind)
+		fmt.Fprintln(w, "NAME\tVERSION\tTYPE\tREQUIRED BY")
+		for _, v := range target.Dependencies(lib, cli) {
+			kind := "direct"
+			if v.Indirect {
+				kind = "indirect"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Path, v.Version, kind, v.RequiredBy)
+		}
+		for _, v := range target.Tools(lib, opt) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Name, v.Version, "tool", v.RequiredBy)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	return 0
+}
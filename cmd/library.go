@@ -3,8 +3,8 @@ package cmd
 import (
 	"os"
 
-	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
 	"github.com/nao1215/mkgoprj/v2/internal/project"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -19,22 +19,18 @@ You need to specify IMPORT_PATH as argument. ※ IMPORT_PATH is same as $ go mod
 }
 
 func init() {
-	libraryCmd.Flags().BoolP("no-root", "n", false, "Create files in the current directory without creating the project root directory")
+	addProjectFlags(libraryCmd)
 	rootCmd.AddCommand(libraryCmd)
 }
 
 func library(cmd *cobra.Command, args []string) int {
 	if len(args) == 0 {
-		ioutils.Die("need import path or project name")
+		newReporter(cmd).Fatal(report.CodeInvalidArgument, "need import path or project name")
 	}
 
-	noRoot, err := cmd.Flags().GetBool("no-root")
-	if err != nil {
-		ioutils.Die("can not parse command line argument (--no-root)")
-	}
-
-	prj := project.NewProject(args[0], true, false, noRoot)
-	prj.Make()
+	importPath, noRoot, opt := parseProjectFlags(cmd, args)
+	prj := project.NewProject(importPath, true, false, noRoot, opt)
+	makeProject(cmd, prj)
 
 	return 0
 }
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/nao1215/mkgoprj/v2/internal/config"
+	"github.com/nao1215/mkgoprj/v2/internal/gotool"
+	"github.com/nao1215/mkgoprj/v2/internal/print"
+	"github.com/nao1215/mkgoprj/v2/internal/project"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/nao1215/mkgoprj/v2/internal/target"
+	"github.com/spf13/cobra"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Make golang project interactively",
+	Long: `Make golang project interactively.
+mkgoprj asks the project kind, import path and options, shows the files to be created,
+and prints the equivalent command line (e.g. "$ mkgoprj cli --docker IMPORT_PATH") at the end.
+The default answers are the default values of cli/library options, including the config files
+(see "$ mkgoprj config --help"). The options that are not asked (e.g. --timeout) are also
+read from the config files.
+
+mkgoprj always generates GitHub Actions workflows and does not generate LICENSE,
+so the license and the CI provider are not asked.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(newProject(cmd, args))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+}
+
+// wizardAnswer is the answers of the interactive wizard.
+type wizardAnswer struct {
+	kind       string // "cli" or "library"
+	importPath string
+	noRoot     bool
+	opt        project.Option
+}
+
+func newProject(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	if err := gotool.CanUseGoCmd(); err != nil {
+		return rep.Error(report.CodeGoNotFound, err.Error())
+	}
+
+	def, prefix := wizardDefaults(cmd)
+	ans, err := askProject(def, prefix)
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not read the answer: "+err.Error())
+	}
+	ans.opt.Reporter = rep
+	ans.opt.Verbose, _ = cmd.Flags().GetBool("verbose")
+	lib := ans.kind == libraryCmd.Name()
+	prj := project.NewProject(ans.importPath, lib, !lib, ans.noRoot, ans.opt)
+
+	rep.Info("mkgoprj will create the following files")
+	prj.PrintPlan()
+	if !print.Question("Do you create the above project?") {
+		rep.Info("canceled")
+		return 0
+	}
+
+	makeProject(cmd, prj)
+	rep.Info("you can create the same project with the following command")
+	rep.Info(ans.commandLine(def))
+	return 0
+}
+
+// wizardDefaults returns the default answers and the import prefix. They are same as
+// the default values of cli/library options, so the values in the config files and
+// the options that the wizard does not ask (e.g. --timeout) are same as "$ mkgoprj cli".
+// If the config file is broken, exit command.
+func wizardDefaults(cmd *cobra.Command) (wizardAnswer, string) {
+	// Use new command so that the options of new subcommand are not changed.
+	c := &cobra.Command{Use: cmd.Use}
+	addProjectFlags(c)
+	addDockerFlag(c)
+	addOutputFlags(c.Flags())
+	// The import path is asked later, so it is not used here.
+	_, noRoot, opt := parseProjectFlags(c, []string{""})
+	opt.Docker, _ = c.Flags().GetBool("docker")
+	if opt.GoVersion == "" {
+		opt.GoVersion = gotool.Version()
+	}
+
+	prefix := ""
+	if cfg, err := config.Load("."); err == nil {
+		prefix, _ = cfg.Get(config.ImportPrefixKey)
+	}
+	return wizardAnswer{noRoot: noRoot, opt: opt}, prefix
+}
+
+// askProject asks the project kind, import path and options. The answers in def are
+// shown as the default, and the import prefix is added to the import path without domain.
+// If it can not read the answer, it returns error.
+func askProject(def wizardAnswer, prefix string) (wizardAnswer, error) {
+	ans := def
+	var err error
+	if ans.kind, err = print.Choice("Which kind of project do you create?",
+		[]string{cliCmd.Name(), libraryCmd.Name()}, cliCmd.Name()); err != nil {
+		return ans, err
+	}
+	ask := "Import path (same as '$ go mod init IMPORT_PATH')?"
+	if prefix != "" {
+		ask = "Import path (same as '$ go mod init IMPORT_PATH', '" + prefix + "/' is added without domain)?"
+	}
+	checkImportPath := func(path string) error {
+		return project.CheckImportPath(config.ExpandImportPath(prefix, path))
+	}
+	if ans.importPath, err = print.Input(ask, "", checkImportPath); err != nil {
+		return ans, err
+	}
+	ans.importPath = config.ExpandImportPath(prefix, ans.importPath)
+	name := project.Name(ans.importPath)
+
+	if ans.opt.Output, err = print.Input("Output directory (empty means the current directory)?", def.opt.Output, nil); err != nil {
+		return ans, err
+	}
+	ans.noRoot = !print.Confirm("Do you create the project root directory '"+name+"'?", !def.noRoot)
+
+	if ans.kind == cliCmd.Name() {
+		if ans.opt.BinName, err = print.Input("Binary name?", target.BinaryName(name), checkBinName); err != nil {
+			return ans, err
+		}
+		ans.opt.Docker = print.Confirm("Do you generate Dockerfile and docker build workflow?", def.opt.Docker)
+	} else {
+		ans.opt.Docker = false
+		if ans.opt.Package, err = print.Input("Package name?", target.PackageName(name), checkPackage); err != nil {
+			return ans, err
+		}
+	}
+
+	if ans.opt.LintPreset, err = print.Choice("Which golangci-lint preset do you use?",
+		target.LintPresets(), def.opt.LintPreset); err != nil {
+		return ans, err
+	}
+	if ans.opt.Hooks, err = print.Choice("Which local git hooks do you generate?",
+		target.HookStyles(), def.opt.Hooks); err != nil {
+		return ans, err
+	}
+	ans.opt.Devcontainer = print.Confirm("Do you generate devcontainer, VS Code settings and .editorconfig?", def.opt.Devcontainer)
+	if ans.opt.GoVersion, err = print.Input("Go version?", def.opt.GoVersion, checkGoVersion); err != nil {
+		return ans, err
+	}
+	ans.opt.Offline = print.Confirm("Do you write go.mod and go.sum without network access (offline mode)?", def.opt.Offline)
+	if !ans.opt.Offline {
+		// --vendor and --module-mirror in the config files can be used only with --offline.
+		ans.opt.Vendor, ans.opt.ModuleMirror = false, ""
+	}
+	ans.opt.Verify = print.Confirm("Do you build, vet and test the project after creating it?", def.opt.Verify)
+	return ans, nil
+}
+
+// commandLine returns the non-interactive command line that creates the same project.
+// Options that are same as def (the default value including the config files) are omitted,
+// so the command line creates the same project with the same config files.
+func (ans wizardAnswer) commandLine(def wizardAnswer) string {
+	name := project.Name(ans.importPath)
+	args := []string{"mkgoprj", ans.kind}
+	if ans.opt.Output != def.opt.Output {
+		args = append(args, "--output", shellQuote(ans.opt.Output))
+	}
+	args = appendBoolFlag(args, "no-root", ans.noRoot, def.noRoot)
+	if ans.opt.BinName != "" && ans.opt.BinName != target.BinaryName(name) {
+		args = append(args, "--bin-name", shellQuote(ans.opt.BinName))
+	}
+	if ans.opt.Package != "" && ans.opt.Package != target.PackageName(name) {
+		args = append(args, "--package", ans.opt.Package)
+	}
+	if ans.kind == cliCmd.Name() {
+		args = appendBoolFlag(args, "docker", ans.opt.Docker, def.opt.Docker)
+	}
+	if ans.opt.LintPreset != def.opt.LintPreset {
+		args = append(args, "--lint-preset", ans.opt.LintPreset)
+	}
+	if ans.opt.Hooks != def.opt.Hooks {
+		args = append(args, "--hooks", ans.opt.Hooks)
+	}
+	args = appendBoolFlag(args, "devcontainer", ans.opt.Devcontainer, def.opt.Devcontainer)
+	// go version is always written because the default depends on the installed go.
+	args = append(args, "--go", ans.opt.GoVersion)
+	args = appendBoolFlag(args, "offline", ans.opt.Offline, def.opt.Offline)
+	// --vendor and --module-mirror in the config files are cleared if offline mode is not used.
+	args = appendBoolFlag(args, "vendor", ans.opt.Vendor, def.opt.Vendor)
+	if ans.opt.ModuleMirror != def.opt.ModuleMirror {
+		args = append(args, "--module-mirror", shellQuote(ans.opt.ModuleMirror))
+	}
+	args = appendBoolFlag(args, "verify", ans.opt.Verify, def.opt.Verify)
+	args = append(args, ans.importPath)
+	return strings.Join(args, " ")
+}
+
+// appendBoolFlag appends "--name" or "--name=false" to args if value is different from def.
+func appendBoolFlag(args []string, name string, value, def bool) []string {
+	if value == def {
+		return args
+	}
+	if value {
+		return append(args, "--"+name)
+	}
+	return append(args, "--"+name+"=false")
+}
+
+// shellQuote quotes s with single quotes if it has characters that shell interprets.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t'\"$`\\*?;&|<>()~#") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
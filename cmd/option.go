@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nao1215/mkgoprj/v2/internal/config"
+	"github.com/nao1215/mkgoprj/v2/internal/gotool"
+	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
+	"github.com/nao1215/mkgoprj/v2/internal/project"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/nao1215/mkgoprj/v2/internal/target"
+	"github.com/spf13/cobra"
+)
+
+// addProjectFlags adds the command line options that are common to all project kinds.
+func addProjectFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("no-root", "n", false, "Create files in the current directory without creating the project root directory")
+	cmd.Flags().StringP("output", "o", "", "Directory where the project is generated (default: current directory)")
+	cmd.Flags().String("dir", "", "Project root directory (default: last element of import path)")
+	cmd.Flags().String("bin-name", "", "Binary name of cli project (default: project name without \"go-\" prefix)")
+	cmd.Flags().String("package", "", "Package name of library project (default: project name without \"go-\" prefix and dashes)")
+	cmd.Flags().String("lint-preset", target.LintPresetStandard,
+		"golangci-lint preset for .golangci.yml ("+strings.Join(target.LintPresets(), "/")+")")
+	cmd.Flags().String("hooks", target.HooksNone,
+		"Local git hooks that run gofmt, go vet, lint and Conventional Commits check ("+strings.Join(target.HookStyles(), "/")+")")
+	cmd.Flags().Bool("devcontainer", false, "Generate .devcontainer/devcontainer.json, .vscode settings and debug configuration, and .editorconfig")
+	cmd.Flags().String("go", "", "Go version for go.mod, workflows and Dockerfile (default: $ go env GOVERSION)")
+	cmd.Flags().Bool("offline", false, "Write go.mod and go.sum with pinned versions instead of network-dependent 'go mod tidy'")
+	cmd.Flags().Bool("vendor", false, "Populate vendor directory (only with --offline)")
+	cmd.Flags().String("module-mirror", "", "Directory used as GOPROXY=file:// (only with --offline, default: local module cache)")
+	cmd.Flags().String("goflags", "", "GOFLAGS passed to go command (default: $GOFLAGS)")
+	cmd.Flags().String("goproxy", "", "GOPROXY passed to go command (default: $GOPROXY)")
+	cmd.Flags().Duration("timeout", 10*time.Minute, "Time limit of go commands (0 means no limit)")
+	cmd.Flags().Bool("verify", os.Getenv("CI") != "", "Build, vet and test the generated project (default true if $CI is set)")
+	cmd.Flags().Bool("keep-partial", false, "Keep the created files when mkgoprj fails or is interrupted")
+	cmd.Flags().String("output-format", report.FormatText,
+		"Output format ("+strings.Join(report.Formats(), "/")+"). json writes one JSON event per line to STDOUT")
+}
+
+// parseProjectFlags returns the import path, the value of --no-root and the options added by addProjectFlags.
+// The options that are not specified in command line are read from the config files,
+// and the import prefix in the config files is added to the import path (args[0]).
+// If the option is invalid, exit command.
+func parseProjectFlags(cmd *cobra.Command, args []string) (string, bool, project.Option) {
+	cfg, cfgErr := applyConfig(cmd)
+	rep := newReporter(cmd)
+	if cfgErr != nil {
+		rep.Fatal(report.CodeInvalidArgument, cfgErr.Error())
+	}
+	prefix, _ := cfg.Get(config.ImportPrefixKey)
+	importPath := config.ExpandImportPath(prefix, args[0])
+
+	noRoot, err := cmd.Flags().GetBool("no-root")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--no-root)")
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--output)")
+	}
+	if ioutils.IsFile(output) {
+		rep.Fatal(report.CodeInvalidArgument, "output directory '"+output+"' is not a directory")
+	}
+
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--dir)")
+	}
+	if dir != "" && noRoot {
+		rep.Fatal(report.CodeInvalidArgument, "--dir can not be used with --no-root")
+	}
+
+	binName, err := cmd.Flags().GetString("bin-name")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--bin-name)")
+	}
+	if binName != "" {
+		if err := checkBinName(binName); err != nil {
+			rep.Fatal(report.CodeInvalidArgument, err.Error())
+		}
+	}
+
+	pkg, err := cmd.Flags().GetString("package")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--package)")
+	}
+	if pkg != "" {
+		if err := checkPackage(pkg); err != nil {
+			rep.Fatal(report.CodeInvalidArgument, err.Error())
+		}
+	}
+
+	lintPreset, err := cmd.Flags().GetString("lint-preset")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--lint-preset)")
+	}
+	if err := checkLintPreset(lintPreset); err != nil {
+		rep.Fatal(report.CodeInvalidArgument, err.Error())
+	}
+
+	hooks, err := cmd.Flags().GetString("hooks")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--hooks)")
+	}
+	if err := checkHooks(hooks); err != nil {
+		rep.Fatal(report.CodeInvalidArgument, err.Error())
+	}
+
+	devcontainer, err := cmd.Flags().GetBool("devcontainer")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--devcontainer)")
+	}
+
+	goVersion, err := cmd.Flags().GetString("go")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--go)")
+	}
+	if goVersion != "" {
+		if err := checkGoVersion(goVersion); err != nil {
+			rep.Fatal(report.CodeInvalidArgument, err.Error())
+		}
+	}
+
+	offline, err := cmd.Flags().GetBool("offline")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--offline)")
+	}
+
+	vendor, err := cmd.Flags().GetBool("vendor")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--vendor)")
+	}
+	if vendor && !offline {
+		rep.Fatal(report.CodeInvalidArgument, "--vendor can be used only with --offline")
+	}
+
+	mirror, err := cmd.Flags().GetString("module-mirror")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--module-mirror)")
+	}
+	if mirror != "" {
+		if !offline {
+			rep.Fatal(report.CodeInvalidArgument, "--module-mirror can be used only with --offline")
+		}
+		if mirror, err = filepath.Abs(mirror); err != nil {
+			rep.Fatal(report.CodeInvalidArgument, "can not get absolute path of module mirror: "+err.Error())
+		}
+	}
+
+	goflags, err := cmd.Flags().GetString("goflags")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--goflags)")
+	}
+
+	goproxy, err := cmd.Flags().GetString("goproxy")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--goproxy)")
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--timeout)")
+	}
+	if timeout < 0 {
+		rep.Fatal(report.CodeInvalidArgument, "--timeout must not be negative")
+	}
+
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--verbose)")
+	}
+
+	verify, err := cmd.Flags().GetBool("verify")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--verify)")
+	}
+
+	keepPartial, err := cmd.Flags().GetBool("keep-partial")
+	if err != nil {
+		rep.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--keep-partial)")
+	}
+
+	opt := project.Option{}
+	opt.Reporter = rep
+	opt.Output = output
+	opt.Dir = dir
+	opt.BinName = binName
+	opt.Package = pkg
+	opt.LintPreset = lintPreset
+	opt.Hooks = hooks
+	opt.Devcontainer = devcontainer
+	opt.GoVersion = goVersion
+	opt.Offline = offline
+	opt.Vendor = vendor
+	opt.ModuleMirror = mirror
+	opt.GoFlags = goflags
+	opt.GoProxy = goproxy
+	opt.Timeout = timeout
+	opt.Verbose = verbose
+	opt.Verify = verify
+	opt.KeepPartial = keepPartial
+	return importPath, noRoot, opt
+}
+
+// applyConfig sets the values in the config files to the options that are not specified
+// in command line. Keys that are not the option of cmd are ignored.
+// If the config file is broken, it returns error.
+func applyConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range cfg.Keys() {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		value, _ := cfg.Get(key)
+		if err := flag.Value.Set(value); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for '%s' in %s: %w", value, key, cfg.Source(key), err)
+		}
+	}
+	return cfg, nil
+}
+
+// checkBinName returns error if binName can not be used as the binary name.
+func checkBinName(binName string) error {
+	if binName == "" || strings.ContainsAny(binName, `/\ `) {
+		return fmt.Errorf("invalid binary name '%s' (it must not be empty or contain '/', '\\' or space)", binName)
+	}
+	return nil
+}
+
+// checkPackage returns error if pkg can not be used as the package name.
+func checkPackage(pkg string) error {
+	if !token.IsIdentifier(pkg) || pkg == "_" {
+		return fmt.Errorf("invalid package name '%s' (it must be go identifier and not keyword)", pkg)
+	}
+	return nil
+}
+
+// checkLintPreset returns error if preset is not golangci-lint preset name.
+func checkLintPreset(preset string) error {
+	if !target.IsLintPreset(preset) {
+		return fmt.Errorf("unknown lint preset '%s' (choose from %s)", preset, strings.Join(target.LintPresets(), ", "))
+	}
+	return nil
+}
+
+// checkHooks returns error if style is not git hooks style name.
+func checkHooks(style string) error {
+	if !target.IsHookStyle(style) {
+		return fmt.Errorf("unknown hooks '%s' (choose from %s)", style, strings.Join(target.HookStyles(), ", "))
+	}
+	return nil
+}
+
+// checkGoVersion returns error if ver can not be written in go.mod.
+func checkGoVersion(ver string) error {
+	if !gotool.IsValidVersion(ver) {
+		return fmt.Errorf("invalid go version '%s' (e.g. 1.22 or 1.22.5)", ver)
+	}
+	return nil
+}
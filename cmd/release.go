@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nao1215/mkgoprj/v2/internal/changelog"
+	"github.com/nao1215/mkgoprj/v2/internal/gittool"
+	"github.com/nao1215/mkgoprj/v2/internal/release"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release " + strings.Join(release.Bumps(), "|"),
+	Short: "Create the annotated tag of the next semantic version",
+	Long: `Create the annotated tag of the next semantic version.
+mkgoprj computes the next version from the latest "vX.Y.Z" tag (v0.0.0 if there is no tag),
+checks that the worktree is clean and "$ make test" passes, moves the Unreleased section of
+Changelog.md to the new version, commits Changelog.md and creates the annotated tag.
+The tag is not pushed. Push it with "$ git push origin <tag>" to start the release workflow.`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: release.Bumps(),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(releaseVersion(cmd, args))
+	},
+}
+
+func init() {
+	releaseCmd.Flags().String("changelog", "Changelog.md", "Changelog file to be updated")
+	releaseCmd.Flags().Bool("dry-run", false, "Print the next version and its changelog without changing anything")
+	rootCmd.AddCommand(releaseCmd)
+}
+
+func releaseVersion(cmd *cobra.Command, args []string) int {
+	rep := newReporter(cmd)
+	ctx := cmd.Context()
+	file, err := cmd.Flags().GetString("changelog")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--changelog)")
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, "can not parse command line argument (--dry-run)")
+	}
+
+	tags, err := gittool.Tags(ctx, ".")
+	if err != nil {
+		return rep.Error(report.CodeGitCommand, err.Error())
+	}
+	latest := release.LatestVersion(tags)
+	version, err := release.NextVersion(latest, args[0])
+	if err != nil {
+		return rep.Error(report.CodeInvalidArgument, err.Error())
+	}
+	for _, v := range tags {
+		if v == version {
+			return rep.Error(report.CodeAlreadyExists, "tag "+version+" already exists")
+		}
+	}
+
+	releases, err := changelog.Log(ctx, ".")
+	if err != nil {
+		return rep.Error(report.CodeGitCommand, err.Error())
+	}
+	releases = changelog.Promote(releases, version, time.Now().Format("2006-01-02"))
+	if dryRun {
+		from := latest
+		if from == "" {
+			from = "(no version tag)"
+		}
+		rep.Info("next version: " + from + " -> " + version)
+		fmt.Fprint(cmd.OutOrStdout(), changelog.Render(releases[1]))
+		return 0
+	}
+
+	step := report.Step{Name: "check-worktree", Description: "check that the worktree is clean"}
+	rep.StepStarted(step)
+	now := time.Now()
+	clean, err := gittool.IsClean(ctx, ".")
+	if err != nil {
+		return rep.Error(report.CodeGitCommand, err.Error())
+	}
+	if !clean {
+		return rep.Error(report.CodeFailedPrecondition, "the worktree has changes. Commit or stash them before release")
+	}
+	rep.StepFinished(step, time.Since(now))
+
+	step = report.Step{Name: "make-test", Description: "Execute 'make test'"}
+	rep.StepStarted(step)
+	now = time.Now()
+	if err := makeTest(cmd, rep); err != nil {
+		return rep.Error(report.CodeVerification, err.Error())
+	}
+	rep.StepFinished(step, time.Since(now))
+
+	step = report.Step{Name: "update-changelog", Description: "move Unreleased section of " + file + " to " + version}
+	rep.StepStarted(step)
+	now = time.Now()
+	current, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	if err := os.WriteFile(file, []byte(changelog.Update(string(current), releases)), 0644); err != nil {
+		return rep.Error(report.CodeIO, err.Error())
+	}
+	if err := gittool.Commit(ctx, ".", "chore(release): "+version, file); err != nil {
+		return rep.Error(report.CodeGitCommand, err.Error())
+	}
+	rep.StepFinished(step, time.Since(now))
+
+	step = report.Step{Name: "create-tag", Description: "create annotated tag " + version}
+	rep.StepStarted(step)
+	now = time.Now()
+	if err := gittool.AnnotatedTag(ctx, ".", version, "Release "+version); err != nil {
+		return rep.Error(report.CodeGitCommand, err.Error())
+	}
+	rep.StepFinished(step, time.Since(now))
+
+	rep.Info("created " + version + ". Push it with '$ git push origin " + version + "' to start the release workflow")
+	return 0
+}
+
+// makeTest execute "$ make test". The output is shown in verbose mode or when it fails.
+func makeTest(cmd *cobra.Command, rep report.Reporter) error {
+	output := new(bytes.Buffer)
+	c := exec.CommandContext(cmd.Context(), "make", "test")
+	c.Stdout, c.Stderr = output, output
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		stdout, stderr := rep.CommandOutput()
+		c.Stdout, c.Stderr = io.MultiWriter(output, stdout), io.MultiWriter(output, stderr)
+	}
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("'make test' failed: %w\n%s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
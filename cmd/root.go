@@ -1,29 +1,82 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/nao1215/mkgoprj/v2/internal/completion"
+	"github.com/nao1215/mkgoprj/v2/internal/project"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "mkgoprj",
 	Short: `mkgoprj make go project for command line application or library`,
+	// The error is displayed by Reporter in Execute.
+	SilenceErrors: true,
 }
 
-func exitError(msg interface{}) {
-	fmt.Fprintln(os.Stderr, msg)
-	os.Exit(1)
+func init() {
+	addOutputFlags(rootCmd.PersistentFlags())
+}
+
+// addOutputFlags adds the options that control the messages of all subcommands.
+func addOutputFlags(flags *pflag.FlagSet) {
+	flags.BoolP("quiet", "q", false, "Show only warnings and errors")
+	flags.BoolP("verbose", "v", false, "Show the output of go commands and debug messages")
+	flags.Bool("no-color", false, "Disable colored output (same as NO_COLOR environment variable)")
+}
+
+// newReporter returns Reporter configured by --quiet, --verbose, --no-color and --output-format
+// (only the subcommands that have it). If the option is invalid, exit command.
+func newReporter(cmd *cobra.Command) report.Reporter {
+	fallback := report.NewText(report.Options{})
+
+	opt := report.Options{}
+	var err error
+	if opt.Quiet, err = cmd.Flags().GetBool("quiet"); err != nil {
+		fallback.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--quiet)")
+	}
+	if opt.Verbose, err = cmd.Flags().GetBool("verbose"); err != nil {
+		fallback.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--verbose)")
+	}
+	if opt.NoColor, err = cmd.Flags().GetBool("no-color"); err != nil {
+		fallback.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--no-color)")
+	}
+	if opt.Quiet && opt.Verbose {
+		fallback.Fatal(report.CodeInvalidArgument, "--quiet can not be used with --verbose")
+	}
+
+	format := report.FormatText
+	if cmd.Flags().Lookup("output-format") != nil {
+		if format, err = cmd.Flags().GetString("output-format"); err != nil {
+			fallback.Fatal(report.CodeInvalidArgument, "can not parse command line argument (--output-format)")
+		}
+	}
+	rep, err := report.New(format, opt)
+	if err != nil {
+		fallback.Fatal(report.CodeInvalidArgument, err.Error())
+	}
+	return rep
+}
+
+// makeProject creates the project. SIGINT (Ctrl-C) and SIGTERM are caught only while
+// creating it, so that it can remove the files created halfway. Outside of it
+// (e.g. the prompts of the wizard), the signals kill mkgoprj as usual.
+func makeProject(cmd *cobra.Command, prj *project.Project) {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	prj.Make(ctx)
 }
 
 // Execute start command.
 func Execute() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
-	completion.DeployShellCompletionFileIfNeeded(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
-		exitError(err)
+		// Unknown subcommand or option. The options may be broken, so the default Reporter is used.
+		report.NewText(report.Options{}).Fatal(report.CodeInvalidArgument, err.Error())
 	}
 }
@@ -0,0 +1,223 @@
+// Package changelog updates Changelog.md in Keep a Changelog format
+// (https://keepachangelog.com/en/1.0.0/) from the git history written in
+// Conventional Commits (https://www.conventionalcommits.org/).
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nao1215/mkgoprj/v2/internal/gittool"
+)
+
+// Unreleased is the version of the commits after the latest tag.
+const Unreleased = "Unreleased"
+
+// DefaultHeader is the header of Changelog.md that does not exist yet.
+const DefaultHeader = `# Changelog
+All notable changes to this project will be documented in this file.  
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/).   
+This project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// Commit is the commit written in Conventional Commits.
+type Commit struct {
+	Hash        string // abbreviated commit hash
+	Type        string // commit type (e.g. "feat")
+	Scope       string // commit scope. Empty means no scope
+	Description string // description after "<type>: "
+	Breaking    bool   // whether the commit has "!" or "BREAKING CHANGE:" footer
+}
+
+// Release is the commits between the tag and the previous tag.
+type Release struct {
+	Version string   // tag name, or Unreleased
+	Date    string   // date of the tagged commit (YYYY-MM-DD). Empty for Unreleased
+	Commits []Commit // commits written in Conventional Commits, newest first
+}
+
+// sections is Keep a Changelog sections in the output order and the commit types in them.
+// Commits of the other types (e.g. docs, test, ci, chore) are not user-visible, so they are skipped.
+var sections = []struct {
+	title string
+	types []string
+}{
+	{title: "Added", types: []string{"feat"}},
+	{title: "Changed", types: []string{"perf", "refactor", "revert"}},
+	{title: "Fixed", types: []string{"fix"}},
+	{title: "Security", types: []string{"security"}},
+}
+
+// subjectRegexp matches "<type>[(scope)][!]: <description>".
+var subjectRegexp = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]+)\))?(!)?: (.+)$`)
+
+// ParseCommit parses the commit message. It returns false if the subject
+// does not follow Conventional Commits.
+func ParseCommit(hash, subject, body string) (Commit, bool) {
+	m := subjectRegexp.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return Commit{}, false
+	}
+	return Commit{
+		Hash:        hash,
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[2],
+		Description: m[4],
+		Breaking:    m[3] == "!" || strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:"),
+	}, true
+}
+
+// Log reads the git history of the repository in dir, and returns the releases newest first.
+// The commits after the latest tag are in the Unreleased release, which is always the first.
+func Log(ctx context.Context, dir string) ([]Release, error) {
+	// %x1f separates fields and %x1e separates commits, because the body has new lines.
+	out, err := gittool.Run(ctx, dir, "log", "--decorate-refs=refs/tags/", "--date=short",
+		"--format=%h%x1f%ad%x1f%D%x1f%s%x1f%b%x1e")
+	if err != nil {
+		return nil, err
+	}
+
+	releases := []Release{{Version: Unreleased}}
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		hash, date, refs, subject, body := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+		if tag := firstTag(refs); tag != "" {
+			releases = append(releases, Release{Version: tag, Date: date})
+		}
+		if c, ok := ParseCommit(hash, subject, body); ok {
+			last := &releases[len(releases)-1]
+			last.Commits = append(last.Commits, c)
+		}
+	}
+	return releases, nil
+}
+
+// firstTag returns the first tag in the ref names of "git log --format=%D" (e.g. "tag: v1.0.0, tag: v1").
+func firstTag(refs string) string {
+	for _, v := range strings.Split(refs, ",") {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "tag: ") {
+			return strings.TrimPrefix(v, "tag: ")
+		}
+	}
+	return ""
+}
+
+// Promote moves the commits in the Unreleased release to the new release of version,
+// and returns the releases that start with the empty Unreleased release.
+func Promote(releases []Release, version, date string) []Release {
+	promoted := []Release{{Version: Unreleased}}
+	for _, r := range releases {
+		if r.Version == Unreleased {
+			r.Version, r.Date = version, date
+		}
+		promoted = append(promoted, r)
+	}
+	return promoted
+}
+
+// Render returns the Markdown of the release (e.g. "## [v1.0.0] - 2024-01-02" and its sections).
+func Render(r Release) string {
+	var sb strings.Builder
+	if r.Date == "" {
+		fmt.Fprintf(&sb, "## [%s]\n", r.Version)
+	} else {
+		fmt.Fprintf(&sb, "## [%s] - %s\n", r.Version, r.Date)
+	}
+
+	for _, s := range sections {
+		lines := []string{}
+		for _, c := range r.Commits {
+			if !contains(s.types, c.Type) {
+				continue
+			}
+			line := "- "
+			if c.Breaking {
+				line += "**BREAKING** "
+			}
+			if c.Scope != "" {
+				line += "**" + c.Scope + ":** "
+			}
+			lines = append(lines, line+c.Description+" ("+c.Hash+")")
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "### %s\n%s\n", s.title, strings.Join(lines, "\n"))
+	}
+	return sb.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Update returns Changelog.md that has the releases. The header (text before the first
+// "## " heading) and the sections of the released versions already in current are kept,
+// so that manual edits are not lost. The Unreleased section is always regenerated.
+// If current is empty, DefaultHeader is used.
+func Update(current string, releases []Release) string {
+	header, order, existing := parse(current)
+	if strings.TrimSpace(header) == "" {
+		header = DefaultHeader
+	}
+
+	blocks := []string{strings.TrimRight(header, "\n") + "\n"}
+	written := map[string]bool{}
+	for _, r := range releases {
+		if text, ok := existing[r.Version]; ok && r.Version != Unreleased {
+			blocks = append(blocks, text)
+		} else {
+			blocks = append(blocks, Render(r))
+		}
+		written[r.Version] = true
+	}
+	// Sections that are not in git history (e.g. written by hand before the tags) are kept at the end.
+	for _, v := range order {
+		if !written[v] {
+			blocks = append(blocks, existing[v])
+		}
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// headingRegexp matches "## [version]" or "## version" and captures the version.
+var headingRegexp = regexp.MustCompile(`^## \[?([^\]\s]+)\]?`)
+
+// parse splits Changelog.md into the header and the sections of versions.
+// The section text has no trailing blank lines.
+func parse(text string) (header string, order []string, sections map[string]string) {
+	sections = map[string]string{}
+	var cur string
+	var buf []string
+	flush := func() {
+		body := strings.TrimRight(strings.Join(buf, "\n"), "\n") + "\n"
+		if cur == "" {
+			header = body
+		} else if _, ok := sections[cur]; !ok {
+			sections[cur] = body
+			order = append(order, cur)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if m := headingRegexp.FindStringSubmatch(line); m != nil {
+			flush()
+			cur, buf = m[1], nil
+		}
+		buf = append(buf, line)
+	}
+	flush()
+	return header, order, sections
+}
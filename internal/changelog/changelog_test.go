@@ -0,0 +1,160 @@
+package changelog
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		subject string
+		body    string
+		want    Commit
+		wantOK  bool
+	}{
+		{subject: "feat: add --output option", want: Commit{Type: "feat", Description: "add --output option"}, wantOK: true},
+		{subject: "fix(cmd): handle empty argument", want: Commit{Type: "fix", Scope: "cmd", Description: "handle empty argument"}, wantOK: true},
+		{subject: "feat(api)!: drop v1", want: Commit{Type: "feat", Scope: "api", Description: "drop v1", Breaking: true}, wantOK: true},
+		{subject: "refactor: rename option", body: "BREAKING CHANGE: --foo is renamed", want: Commit{Type: "refactor", Description: "rename option", Breaking: true}, wantOK: true},
+		{subject: "Feat: upper case type", want: Commit{Type: "feat", Description: "upper case type"}, wantOK: true},
+		{subject: "update README", wantOK: false},
+		{subject: "Merge pull request #1 from foo/bar", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseCommit("", tt.subject, tt.body)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseCommit(%q) = %+v, %v, want %+v, %v", tt.subject, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	releases := []Release{
+		{Version: Unreleased, Commits: []Commit{
+			{Hash: "ccc", Type: "fix", Description: "fix crash"},
+			{Hash: "bbb", Type: "docs", Description: "update README"},
+		}},
+		{Version: "v1.1.0", Date: "2024-02-01", Commits: []Commit{
+			{Hash: "aaa", Type: "feat", Scope: "cli", Description: "add --json", Breaking: true},
+			{Hash: "999", Type: "perf", Description: "faster write"},
+		}},
+		{Version: "v1.0.0", Date: "2024-01-01"},
+	}
+	current := DefaultHeader + `
+## [Unreleased]
+### Added
+- old unreleased entry
+
+## [v1.0.0] - 2024-01-01
+### Added
+- first release (edited by hand)
+
+## [v0.1.0] - 2023-12-01
+- prototype
+`
+	want := DefaultHeader + `
+## [Unreleased]
+### Fixed
+- fix crash (ccc)
+
+## [v1.1.0] - 2024-02-01
+### Added
+- **BREAKING** **cli:** add --json (aaa)
+### Changed
+- faster write (999)
+
+## [v1.0.0] - 2024-01-01
+### Added
+- first release (edited by hand)
+
+## [v0.1.0] - 2023-12-01
+- prototype
+`
+	if got := Update(current, releases); got != want {
+		t.Errorf("Update() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Update("", releases[2:]); got != DefaultHeader+"\n## [v1.0.0] - 2024-01-01\n" {
+		t.Errorf("Update(\"\") =\n%s", got)
+	}
+}
+
+func TestPromote(t *testing.T) {
+	releases := []Release{
+		{Version: Unreleased, Commits: []Commit{{Hash: "bbb", Type: "fix", Description: "fix crash"}}},
+		{Version: "v1.0.0", Date: "2024-01-01"},
+	}
+	want := []Release{
+		{Version: Unreleased},
+		{Version: "v1.0.1", Date: "2024-02-01", Commits: []Commit{{Hash: "bbb", Type: "fix", Description: "fix crash"}}},
+		{Version: "v1.0.0", Date: "2024-01-01"},
+	}
+	if got := Promote(releases, "v1.0.1", "2024-02-01"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Promote() = %+v, want %+v", got, want)
+	}
+}
+
+// TestLog reads the git history of the fixture repository created in the temporary directory.
+func TestLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=mkgoprj", "GIT_AUTHOR_EMAIL=mkgoprj@example.com",
+			"GIT_COMMITTER_NAME=mkgoprj", "GIT_COMMITTER_EMAIL=mkgoprj@example.com",
+			"GIT_AUTHOR_DATE=2024-01-02T03:04:05Z", "GIT_COMMITTER_DATE=2024-01-02T03:04:05Z",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	commit := func(msg string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(msg), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "file.txt")
+		git("commit", "-q", "-m", msg)
+	}
+
+	git("init", "-q")
+	commit("feat: first feature")
+	commit("chore: setup ci")
+	git("tag", "v0.1.0")
+	commit("fix(cmd): fix crash\n\nBREAKING CHANGE: exit status is changed")
+	git("tag", "-a", "v0.2.0", "-m", "v0.2.0")
+	commit("feat!: new option")
+	commit("not conventional commit")
+
+	releases, err := Log(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range releases {
+		for j := range releases[i].Commits {
+			releases[i].Commits[j].Hash = ""
+		}
+	}
+	want := []Release{
+		{Version: Unreleased, Commits: []Commit{{Type: "feat", Description: "new option", Breaking: true}}},
+		{Version: "v0.2.0", Date: "2024-01-02", Commits: []Commit{{Type: "fix", Scope: "cmd", Description: "fix crash", Breaking: true}}},
+		{Version: "v0.1.0", Date: "2024-01-02", Commits: []Commit{
+			{Type: "chore", Description: "setup ci"},
+			{Type: "feat", Description: "first feature"},
+		}},
+	}
+	if !reflect.DeepEqual(releases, want) {
+		t.Errorf("Log() = %+v\nwant %+v", releases, want)
+	}
+}
@@ -1,257 +1,345 @@
+// Package completion installs, uninstalls and prints the shell completion script.
 package completion
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 
-	"github.com/nao1215/mkgoprj/v2/internal/cmdinfo"
-	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
-	"github.com/nao1215/mkgoprj/v2/internal/print"
 	"github.com/spf13/cobra"
 )
 
-// DeployShellCompletionFileIfNeeded creates the shell completion file.
-// If the file with the same contents already exists, it is not created.
-func DeployShellCompletionFileIfNeeded(cmd *cobra.Command) {
-	if !isWindows() {
-		makeBashCompletionFileIfNeeded(cmd)
-		makeFishCompletionFileIfNeeded(cmd)
-		makeZshCompletionFileIfNeeded(cmd)
-	}
+const (
+	// Bash is bash shell name.
+	Bash = "bash"
+	// Fish is fish shell name.
+	Fish = "fish"
+	// Zsh is zsh shell name.
+	Zsh = "zsh"
+	// PowerShell is PowerShell name.
+	PowerShell = "powershell"
+)
+
+// Shells returns the shell names that support completion.
+func Shells() []string {
+	return []string{Bash, Fish, Zsh, PowerShell}
 }
 
-func isWindows() bool {
-	return runtime.GOOS == "windows"
+// Change is the file operation to install or uninstall the shell completion.
+type Change struct {
+	Path    string // file path to be changed
+	Content []byte // new file content. It is not used when Remove is true
+	Remove  bool   // whether to remove the file
 }
 
-func makeBashCompletionFileIfNeeded(cmd *cobra.Command) {
-	if existSameBashCompletionFile(cmd) {
-		return
+// String returns the description of the file operation.
+func (c Change) String() string {
+	if c.Remove {
+		return "remove " + c.Path
 	}
-
-	path := bashCompletionFilePath()
-	bashCompletion := new(bytes.Buffer)
-	if err := cmd.GenBashCompletion(bashCompletion); err != nil {
-		print.Err(fmt.Errorf("can not generate bash completion content: %w", err))
-		return
+	if _, err := os.Stat(c.Path); err == nil {
+		return "update " + c.Path
 	}
+	return "create " + c.Path
+}
 
-	if !ioutils.IsFile(path) {
-		fp, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0664)
-		if err != nil {
-			print.Err(fmt.Errorf("can not open .bash_completion: %w", err))
-			return
-		}
+// Print writes the completion script of shell to w.
+func Print(cmd *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case Bash:
+		return cmd.GenBashCompletion(w)
+	case Fish:
+		return cmd.GenFishCompletion(w, false)
+	case Zsh:
+		return cmd.GenZshCompletion(w)
+	case PowerShell:
+		return cmd.GenPowerShellCompletion(w)
+	}
+	return unsupportedShellErr(shell)
+}
 
-		if _, err := fp.WriteString(bashCompletion.String()); err != nil {
-			print.Err(fmt.Errorf("can not write .bash_completion %w", err))
-			return
-		}
+// InstallChanges returns the file operations to install the completion of shell.
+// Files that already have the same content are not included, so it returns
+// nothing when the completion is already installed.
+func InstallChanges(cmd *cobra.Command, shell string) ([]Change, error) {
+	return installChanges(cmd, shell, newPaths())
+}
 
-		if err := fp.Close(); err != nil {
-			print.Err(fmt.Errorf("can not close .bash_completion %w", err))
-			return
-		}
-		return
+func installChanges(cmd *cobra.Command, shell string, p paths) ([]Change, error) {
+	script := new(bytes.Buffer)
+	if err := Print(cmd, shell, script); err != nil {
+		return nil, fmt.Errorf("can not generate %s completion: %w", shell, err)
 	}
 
-	fp, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0664)
-	if err != nil {
-		print.Err(fmt.Errorf("can not append .bash_completion: %w", err))
-		return
+	changes := []Change{}
+	scriptPath := p.scriptFile(cmd.Name(), shell)
+	if current, err := os.ReadFile(scriptPath); err != nil || !bytes.Equal(current, script.Bytes()) {
+		changes = append(changes, Change{Path: scriptPath, Content: script.Bytes()})
 	}
 
-	if _, err := fp.WriteString(bashCompletion.String()); err != nil {
-		print.Err(fmt.Errorf("can not write .bash_completion: %w", err))
-		return
+	rc := p.rcFile(shell)
+	if rc == "" {
+		return changes, nil
 	}
-
-	if err := fp.Close(); err != nil {
-		print.Err(fmt.Errorf("can not close .bash_completion: %w", err))
-		return
+	current, err := readFileIfExists(rc)
+	if err != nil {
+		return nil, err
+	}
+	updated := upsertBlock(current, cmd.Name(), rcBlockBody(shell, scriptPath))
+	if updated != current {
+		changes = append(changes, Change{Path: rc, Content: []byte(updated)})
 	}
-	print.Info("append bash-completion: " + path)
+	return changes, nil
 }
 
-func makeFishCompletionFileIfNeeded(cmd *cobra.Command) {
-	if isSameFishCompletionFile(cmd) {
-		return
-	}
+// UninstallChanges returns the file operations to uninstall the completion of shell.
+// The completion deployed automatically by old mkgoprj is also removed.
+func UninstallChanges(cmd *cobra.Command, shell string) ([]Change, error) {
+	return uninstallChanges(cmd, shell, newPaths())
+}
 
-	path := fishCompletionFilePath()
-	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
-		print.Err(fmt.Errorf("can not create fish-completion file: %w", err))
-		return
+func uninstallChanges(cmd *cobra.Command, shell string, p paths) ([]Change, error) {
+	if !isSupported(shell) {
+		return nil, unsupportedShellErr(shell)
 	}
 
-	if err := cmd.GenFishCompletionFile(path, false); err != nil {
-		print.Err(fmt.Errorf("can not create fish-completion file: %w", err))
-		return
+	changes := []Change{}
+	scriptPath := p.scriptFile(cmd.Name(), shell)
+	if _, err := os.Stat(scriptPath); err == nil {
+		changes = append(changes, Change{Path: scriptPath, Remove: true})
 	}
-	print.Info("create fish-completion file: " + path)
-}
 
-func makeZshCompletionFileIfNeeded(cmd *cobra.Command) {
-	if isSameZshCompletionFile(cmd) {
-		return
+	rc := p.rcFile(shell)
+	if rc == "" {
+		return changes, nil
 	}
-
-	path := zshCompletionFilePath()
-	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
-		print.Err(fmt.Errorf("can not create zsh-completion file: %w", err))
-		return
+	current, err := readFileIfExists(rc)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := cmd.GenZshCompletionFile(path); err != nil {
-		print.Err(fmt.Errorf("can not create zsh-completion file: %w", err))
-		return
+	updated := removeLegacy(removeBlock(current, cmd.Name()), cmd.Name(), shell)
+	if updated == "" && current != "" {
+		changes = append(changes, Change{Path: rc, Remove: true})
+	} else if updated != current {
+		changes = append(changes, Change{Path: rc, Content: []byte(updated)})
 	}
-	print.Info("create zsh-completion file: " + path)
-
-	appendFpathAtZshrcIfNeeded()
+	return changes, nil
 }
 
-func appendFpathAtZshrcIfNeeded() {
-	const zshFpath = `
-# setting for mkgoprj command (auto generate)
-fpath=(~/.zsh/completion $fpath)
-autoload -Uz compinit && compinit -i
-`
-	zshrcPath := zshrcPath()
-	if !ioutils.IsFile(zshrcPath) {
-		fp, err := os.OpenFile(zshrcPath, os.O_RDWR|os.O_CREATE, 0664)
-		if err != nil {
-			print.Err(fmt.Errorf("can not open .zshrc: %w", err).Error())
-			return
+// Apply executes the file operations.
+func Apply(changes []Change) error {
+	for _, c := range changes {
+		if c.Remove {
+			if err := os.Remove(c.Path); err != nil {
+				return fmt.Errorf("can not remove %s: %w", c.Path, err)
+			}
+			continue
 		}
 
-		if _, err := fp.WriteString(zshFpath); err != nil {
-			print.Err(fmt.Errorf("can not write zsh $fpath in .zshrc: %w", err).Error())
-			return
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0775); err != nil {
+			return fmt.Errorf("can not create directory for %s: %w", c.Path, err)
 		}
-
-		if err := fp.Close(); err != nil {
-			print.Err(fmt.Errorf("can not close .zshrc: %w", err).Error())
-			return
+		if err := os.WriteFile(c.Path, c.Content, 0664); err != nil {
+			return fmt.Errorf("can not write %s: %w", c.Path, err)
 		}
-		return
 	}
+	return nil
+}
 
-	zshrc, err := os.ReadFile(zshrcPath)
-	if err != nil {
-		print.Err(fmt.Errorf("can not read .zshrc: %w", err).Error())
-		return
-	}
+// beginMarker returns the first line of the block that mkgoprj manages in rc file.
+func beginMarker(name string) string {
+	return "# >>> " + name + " completion >>>"
+}
 
-	if strings.Contains(string(zshrc), zshFpath) {
-		return
-	}
+// endMarker returns the last line of the block that mkgoprj manages in rc file.
+func endMarker(name string) string {
+	return "# <<< " + name + " completion <<<"
+}
 
-	fp, err := os.OpenFile(zshrcPath, os.O_RDWR|os.O_APPEND, 0664)
-	if err != nil {
-		print.Err(fmt.Errorf("can not open .zshrc: %w", err).Error())
-		return
-	}
+// upsertBlock replaces the marker-delimited block in content with body.
+// If content does not have the block, the block is appended.
+func upsertBlock(content, name, body string) string {
+	block := beginMarker(name) + "\n" + body + endMarker(name) + "\n"
 
-	if _, err := fp.WriteString(zshFpath); err != nil {
-		print.Err(fmt.Errorf("can not write zsh $fpath in .zshrc: %w", err).Error())
-		return
+	begin := strings.Index(content, beginMarker(name))
+	end := strings.Index(content, endMarker(name))
+	if begin == -1 || end == -1 || end < begin {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + block
 	}
-
-	if err := fp.Close(); err != nil {
-		print.Err(fmt.Errorf("can not close .zshrc: %w", err).Error())
-		return
+	end += len(endMarker(name))
+	if end < len(content) && content[end] == '\n' {
+		end++
 	}
+	return content[:begin] + block + content[end:]
 }
 
-func existSameBashCompletionFile(cmd *cobra.Command) bool {
-	if !ioutils.IsFile(bashCompletionFilePath()) {
-		return false
+// removeBlock removes the marker-delimited block from content.
+func removeBlock(content, name string) string {
+	begin := strings.Index(content, beginMarker(name))
+	end := strings.Index(content, endMarker(name))
+	if begin == -1 || end == -1 || end < begin {
+		return content
 	}
-	return hasSameBashCompletionContent(cmd)
+	end += len(endMarker(name))
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:begin] + content[end:]
 }
 
-func hasSameBashCompletionContent(cmd *cobra.Command) bool {
-	bashCompletionFileInLocal, err := os.ReadFile(bashCompletionFilePath())
-	if err != nil {
-		print.Err(fmt.Errorf("can not read .bash_completion: %w", err).Error())
-		return false
+// rcBlockBody returns the text in the marker-delimited block of rc file.
+func rcBlockBody(shell, scriptPath string) string {
+	switch shell {
+	case Bash:
+		return fmt.Sprintf("[ -f %q ] && . %q\n", scriptPath, scriptPath)
+	case Zsh:
+		return fmt.Sprintf("fpath=(%q $fpath)\nautoload -Uz compinit && compinit -i\n", filepath.Dir(scriptPath))
+	case PowerShell:
+		return fmt.Sprintf(". '%s'\n", strings.ReplaceAll(scriptPath, "'", "''"))
 	}
+	return ""
+}
 
-	currentBashCompletion := new(bytes.Buffer)
-	if err := cmd.GenBashCompletion(currentBashCompletion); err != nil {
-		return false
-	}
-	if !strings.Contains(string(bashCompletionFileInLocal), currentBashCompletion.String()) {
-		return false
-	}
-	return true
+// legacyZshContent returns the content that old mkgoprj wrote to .zshrc without markers.
+func legacyZshContent(name string) string {
+	return `
+# setting for ` + name + ` command (auto generate)
+fpath=(~/.zsh/completion $fpath)
+autoload -Uz compinit && compinit -i
+`
 }
 
-func isSameFishCompletionFile(cmd *cobra.Command) bool {
-	path := fishCompletionFilePath()
-	if !ioutils.IsFile(path) {
-		return false
-	}
+// legacyBashHeader and legacyBashTrailer are the first and last lines of the bash completion
+// script that old mkgoprj appended to .bash_completion without markers. The script between
+// them depends on the commands of old mkgoprj, so it can not be compared with the current one.
+const (
+	legacyBashHeader  = "# bash completion for %s "
+	legacyBashTrailer = "# ex: ts=4 sw=4 et filetype=sh\n"
+)
 
-	currentFishCompletion := new(bytes.Buffer)
-	if err := cmd.GenFishCompletion(currentFishCompletion, false); err != nil {
-		return false
+// removeLegacy removes the completion that old mkgoprj wrote to rc file without markers.
+func removeLegacy(content, name, shell string) string {
+	switch shell {
+	case Bash:
+		header := fmt.Sprintf(legacyBashHeader, name)
+		for {
+			begin := -1
+			if strings.HasPrefix(content, header) {
+				begin = 0
+			} else if i := strings.Index(content, "\n"+header); i != -1 {
+				begin = i + 1
+			}
+			if begin == -1 {
+				return content
+			}
+			end := strings.Index(content[begin:], legacyBashTrailer)
+			if end == -1 {
+				return content
+			}
+			content = content[:begin] + content[begin+end+len(legacyBashTrailer):]
+		}
+	case Zsh:
+		return strings.ReplaceAll(content, legacyZshContent(name), "")
 	}
+	return content
+}
 
-	fishCompletionInLocal, err := os.ReadFile(path)
-	if err != nil {
-		return false
-	}
+// paths resolves the completion file paths. OS and home directory are
+// fields (not runtime.GOOS and $HOME) so that the path logic of every OS can
+// be tested on any OS.
+type paths struct {
+	goos string // same as runtime.GOOS
+	home string // user home directory
+}
 
-	if bytes.Compare(currentFishCompletion.Bytes(), fishCompletionInLocal) != 0 {
-		return false
+// newPaths returns paths for the running system.
+func newPaths() paths {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
 	}
-	return true
+	return paths{goos: runtime.GOOS, home: home}
 }
 
-func isSameZshCompletionFile(cmd *cobra.Command) bool {
-	path := zshCompletionFilePath()
-	if !ioutils.IsFile(path) {
-		return false
+// scriptFile returns the completion script file path.
+func (p paths) scriptFile(name, shell string) string {
+	switch shell {
+	case Bash:
+		return filepath.Join(p.home, ".local", "share", "bash-completion", "completions", name)
+	case Fish:
+		return filepath.Join(p.home, ".config", "fish", "completions", name+".fish")
+	case Zsh:
+		return filepath.Join(p.home, ".zsh", "completion", "_"+name)
+	case PowerShell:
+		return filepath.Join(filepath.Dir(p.powerShellProfile()), "completions", name+".ps1")
 	}
+	return ""
+}
 
-	currentZshCompletion := new(bytes.Buffer)
-	if err := cmd.GenZshCompletion(currentZshCompletion); err != nil {
-		return false
+// rcFile returns the file that loads the completion script.
+// It returns empty string if the shell loads the script automatically.
+func (p paths) rcFile(shell string) string {
+	switch shell {
+	case Bash:
+		return filepath.Join(p.home, ".bash_completion")
+	case Zsh:
+		return filepath.Join(p.home, ".zshrc")
+	case PowerShell:
+		return p.powerShellProfile()
 	}
+	return ""
+}
 
-	zshCompletionInLocal, err := os.ReadFile(path)
-	if err != nil {
-		return false
+// powerShellProfile returns $PROFILE (CurrentUserCurrentHost) of PowerShell.
+// On Windows, Windows PowerShell 5.1 profile is used only when PowerShell 7
+// profile directory does not exist and Windows PowerShell one exists.
+func (p paths) powerShellProfile() string {
+	const profile = "Microsoft.PowerShell_profile.ps1"
+	if p.goos != "windows" {
+		return filepath.Join(p.home, ".config", "powershell", profile)
 	}
 
-	if bytes.Compare(currentZshCompletion.Bytes(), zshCompletionInLocal) != 0 {
-		return false
+	pwsh := filepath.Join(p.home, "Documents", "PowerShell")
+	windowsPowerShell := filepath.Join(p.home, "Documents", "WindowsPowerShell")
+	if !isDir(pwsh) && isDir(windowsPowerShell) {
+		return filepath.Join(windowsPowerShell, profile)
 	}
-	return true
+	return filepath.Join(pwsh, profile)
 }
 
-// bashCompletionFilePath return bash-completion file path.
-func bashCompletionFilePath() string {
-	return filepath.Join(os.Getenv("HOME"), ".bash_completion")
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
 }
 
-// fishCompletionFilePath return fish-completion file path.
-func fishCompletionFilePath() string {
-	return filepath.Join(os.Getenv("HOME"), ".config", "fish", "completions", cmdinfo.Name+".fish")
+func readFileIfExists(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("can not read %s: %w", path, err)
+	}
+	return string(b), nil
 }
 
-// zshCompletionFilePath return zsh-completion file path.
-func zshCompletionFilePath() string {
-	return filepath.Join(os.Getenv("HOME"), ".zsh", "completion", "_"+cmdinfo.Name)
+func isSupported(shell string) bool {
+	for _, v := range Shells() {
+		if v == shell {
+			return true
+		}
+	}
+	return false
 }
 
-// zshrcPath return .zshrc path.
-func zshrcPath() string {
-	return filepath.Join(os.Getenv("HOME"), ".zshrc")
+func unsupportedShellErr(shell string) error {
+	return fmt.Errorf("unsupported shell '%s' (choose from %s)", shell, strings.Join(Shells(), ", "))
 }
@@ -0,0 +1,232 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+const testBlock = "# >>> mkgoprj completion >>>\nsource x\n# <<< mkgoprj completion <<<\n"
+
+func TestUpsertBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "empty file", content: "", want: testBlock},
+		{name: "append to user content", content: "alias ll='ls -l'", want: "alias ll='ls -l'\n" + testBlock},
+		{name: "same block", content: testBlock, want: testBlock},
+		{
+			name:    "replace old block and keep user content",
+			content: "export A=1\n# >>> mkgoprj completion >>>\nsource old\n# <<< mkgoprj completion <<<\nexport B=2\n",
+			want:    "export A=1\n" + testBlock + "export B=2\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := upsertBlock(tt.content, "mkgoprj", "source x\n"); got != tt.want {
+			t.Errorf("%s: upsertBlock() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "no block", content: "export A=1\n", want: "export A=1\n"},
+		{name: "only block", content: testBlock, want: ""},
+		{name: "keep user content", content: "export A=1\n" + testBlock + "export B=2\n", want: "export A=1\nexport B=2\n"},
+		{name: "other command block", content: strings.ReplaceAll(testBlock, "mkgoprj", "other"), want: strings.ReplaceAll(testBlock, "mkgoprj", "other")},
+	}
+	for _, tt := range tests {
+		if got := removeBlock(tt.content, "mkgoprj"); got != tt.want {
+			t.Errorf("%s: removeBlock() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestInstallUninstall installs the completion to the temporary home directory twice,
+// and uninstalls it. The user content in rc file is kept.
+func TestInstallUninstall(t *testing.T) {
+	cmd := &cobra.Command{Use: "mkgoprj"}
+	for _, shell := range []string{Bash, Zsh, Fish, PowerShell} {
+		t.Run(shell, func(t *testing.T) {
+			p := paths{goos: "linux", home: t.TempDir()}
+			const user = "# user setting\nexport A=1\n"
+			rc := p.rcFile(shell)
+			if rc != "" {
+				writeFile(t, rc, user)
+			}
+
+			for i := 0; i < 2; i++ {
+				changes, err := installChanges(cmd, shell, p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i == 1 && len(changes) != 0 {
+					t.Errorf("second install changes %v, want nothing", changes)
+				}
+				if err := Apply(changes); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if rc != "" {
+				got := readFile(t, rc)
+				if !strings.HasPrefix(got, user) || strings.Count(got, beginMarker("mkgoprj")) != 1 {
+					t.Errorf("rc file after install twice =\n%s", got)
+				}
+			}
+
+			changes, err := uninstallChanges(cmd, shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Apply(changes); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(p.scriptFile("mkgoprj", shell)); !os.IsNotExist(err) {
+				t.Errorf("completion script is not removed: %v", err)
+			}
+			if rc != "" {
+				if got := readFile(t, rc); got != user {
+					t.Errorf("rc file after uninstall = %q, want %q", got, user)
+				}
+			}
+
+			// Uninstall without the completion is no-op.
+			if changes, err := uninstallChanges(cmd, shell, p); err != nil || len(changes) != 0 {
+				t.Errorf("uninstall again = %v, %v, want nothing", changes, err)
+			}
+		})
+	}
+}
+
+// TestUninstallLegacy removes the completion written by old mkgoprj without markers.
+// testdata/legacy_bash_completion is .bash_completion written by old mkgoprj (before
+// completion subcommand), so the script is different from the current command tree.
+func TestUninstallLegacy(t *testing.T) {
+	cmd := &cobra.Command{Use: "mkgoprj"}
+	legacy := readFile(t, filepath.Join("testdata", "legacy_bash_completion"))
+	other := strings.ReplaceAll(legacy, "mkgoprj", "other")
+	tests := []struct {
+		shell   string
+		content string
+		want    string
+	}{
+		{shell: Bash, content: legacy, want: ""},
+		{shell: Bash, content: "export A=1\n" + legacy + testBlock, want: "export A=1\n"},
+		{shell: Bash, content: other + legacy + "export A=1\n", want: other + "export A=1\n"},
+		{
+			shell:   Zsh,
+			content: "export A=1\n\n# setting for mkgoprj command (auto generate)\nfpath=(~/.zsh/completion $fpath)\nautoload -Uz compinit && compinit -i\n",
+			want:    "export A=1\n",
+		},
+	}
+	for _, tt := range tests {
+		p := paths{goos: "linux", home: t.TempDir()}
+		rc := p.rcFile(tt.shell)
+		writeFile(t, rc, tt.content)
+		changes, err := uninstallChanges(cmd, tt.shell, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Apply(changes); err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if _, err := os.Stat(rc); err == nil {
+			got = readFile(t, rc)
+		}
+		if got != tt.want {
+			t.Errorf("%s: rc file after uninstall = %q, want %q", tt.shell, got, tt.want)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	home := t.TempDir()
+	tests := []struct {
+		goos   string
+		shell  string
+		script string
+		rc     string
+	}{
+		{goos: "linux", shell: Bash, script: ".local/share/bash-completion/completions/mkgoprj", rc: ".bash_completion"},
+		{goos: "linux", shell: Zsh, script: ".zsh/completion/_mkgoprj", rc: ".zshrc"},
+		{goos: "linux", shell: Fish, script: ".config/fish/completions/mkgoprj.fish", rc: ""},
+		{goos: "linux", shell: PowerShell, script: ".config/powershell/completions/mkgoprj.ps1", rc: ".config/powershell/Microsoft.PowerShell_profile.ps1"},
+		{goos: "darwin", shell: Bash, script: ".local/share/bash-completion/completions/mkgoprj", rc: ".bash_completion"},
+		{goos: "darwin", shell: Zsh, script: ".zsh/completion/_mkgoprj", rc: ".zshrc"},
+		{goos: "darwin", shell: Fish, script: ".config/fish/completions/mkgoprj.fish", rc: ""},
+	}
+	for _, tt := range tests {
+		p := paths{goos: tt.goos, home: home}
+		if got, want := p.scriptFile("mkgoprj", tt.shell), filepath.Join(home, filepath.FromSlash(tt.script)); got != want {
+			t.Errorf("%s/%s: scriptFile() = %s, want %s", tt.goos, tt.shell, got, want)
+		}
+		want := ""
+		if tt.rc != "" {
+			want = filepath.Join(home, filepath.FromSlash(tt.rc))
+		}
+		if got := p.rcFile(tt.shell); got != want {
+			t.Errorf("%s/%s: rcFile() = %s, want %s", tt.goos, tt.shell, got, want)
+		}
+	}
+}
+
+func TestPowerShellProfileOnWindows(t *testing.T) {
+	const profile = "Microsoft.PowerShell_profile.ps1"
+	tests := []struct {
+		name string
+		dirs []string // directories that exist in home
+		want string
+	}{
+		{name: "no profile directory", want: "Documents/PowerShell"},
+		{name: "only PowerShell 7", dirs: []string{"Documents/PowerShell"}, want: "Documents/PowerShell"},
+		{name: "only Windows PowerShell", dirs: []string{"Documents/WindowsPowerShell"}, want: "Documents/WindowsPowerShell"},
+		{name: "both", dirs: []string{"Documents/PowerShell", "Documents/WindowsPowerShell"}, want: "Documents/PowerShell"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			for _, v := range tt.dirs {
+				if err := os.MkdirAll(filepath.Join(home, filepath.FromSlash(v)), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p := paths{goos: "windows", home: home}
+			want := filepath.Join(home, filepath.FromSlash(tt.want), profile)
+			if got := p.rcFile(PowerShell); got != want {
+				t.Errorf("rcFile() = %s, want %s", got, want)
+			}
+			if got, want := p.scriptFile("mkgoprj", PowerShell), filepath.Join(filepath.Dir(want), "completions", "mkgoprj.ps1"); got != want {
+				t.Errorf("scriptFile() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,172 @@
+// Package config reads and writes the default values of mkgoprj command line options.
+//
+// The user config file is $XDG_CONFIG_HOME/mkgoprj/config.yaml. The yaml files in
+// .mkgoprj.d directory of the repository (the current directory or its parent)
+// override the user config. The key is the option name without "--" (e.g. "lint-preset"),
+// and "import-prefix" is the prefix added to the import path without domain.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ImportPrefixKey is the key of the prefix added to the import path without domain.
+	ImportPrefixKey = "import-prefix"
+	// RepoDirName is the directory name of the repository config.
+	RepoDirName = ".mkgoprj.d"
+)
+
+// Config is the merged config. Later files override earlier files.
+type Config struct {
+	values  map[string]string // key=option name, value=option value
+	sources map[string]string // key=option name, value=file that sets the value
+}
+
+// Load reads the user config file and the repository config files found from dir to the root.
+// It returns empty config if there is no config file.
+func Load(dir string) (*Config, error) {
+	c := &Config{values: map[string]string{}, sources: map[string]string{}}
+
+	user, err := UserFile()
+	if err == nil {
+		if err := c.merge(user); err != nil {
+			return nil, err
+		}
+	}
+
+	repoFiles, err := RepoFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range repoFiles {
+		if err := c.merge(v); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *Config) merge(file string) error {
+	values, err := ReadFile(file)
+	if err != nil {
+		return err
+	}
+	for k, v := range values {
+		c.values[k] = v
+		c.sources[k] = file
+	}
+	return nil
+}
+
+// Get returns the value of key and whether the key is set.
+func (c *Config) Get(key string) (string, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// Source returns the file that sets the value of key.
+func (c *Config) Source(key string) string {
+	return c.sources[key]
+}
+
+// Keys returns the keys that are set in sorted order.
+func (c *Config) Keys() []string {
+	keys := []string{}
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UserFile returns the user config file path ($XDG_CONFIG_HOME/mkgoprj/config.yaml).
+func UserFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("can not get user config directory: %w", err)
+	}
+	return filepath.Join(dir, "mkgoprj", "config.yaml"), nil
+}
+
+// RepoFiles returns the yaml files in the nearest .mkgoprj.d directory from dir to the root.
+// The files are sorted by name, so that "10-xxx.yaml" overrides "00-xxx.yaml".
+func RepoFiles(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		repoDir := filepath.Join(dir, RepoDirName)
+		if stat, err := os.Stat(repoDir); err == nil && stat.IsDir() {
+			files := []string{}
+			for _, pattern := range []string{"*.yaml", "*.yml"} {
+				matches, err := filepath.Glob(filepath.Join(repoDir, pattern))
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, matches...)
+			}
+			sort.Strings(files)
+			return files, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// ReadFile reads the config file. It returns empty map if the file does not exist.
+func ReadFile(file string) (map[string]string, error) {
+	values := map[string]string{}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not read config file %s: %w", file, err)
+	}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("can not parse config file %s: %w", file, err)
+	}
+	return values, nil
+}
+
+// WriteFile writes values to the config file. The parent directory is created if it does not exist.
+func WriteFile(file string, values map[string]string) error {
+	b, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("can not create config directory: %w", err)
+	}
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("can not write config file %s: %w", file, err)
+	}
+	return nil
+}
+
+// ExpandImportPath adds prefix to the import path if the first element of the import path
+// is not domain (e.g. prefix="github.com/acme", "foo" -> "github.com/acme/foo").
+func ExpandImportPath(prefix, importPath string) string {
+	if prefix == "" {
+		return importPath
+	}
+	first := strings.SplitN(importPath, "/", 2)[0]
+	if strings.Contains(first, ".") {
+		return importPath
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + importPath
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	writeTestFile(t, filepath.Join(home, "mkgoprj", "config.yaml"),
+		"import-prefix: github.com/acme\nlint-preset: strict\noffline: true\n")
+
+	repo := t.TempDir()
+	writeTestFile(t, filepath.Join(repo, RepoDirName, "00-base.yaml"), "lint-preset: minimal\ngo: 1.20\n")
+	writeTestFile(t, filepath.Join(repo, RepoDirName, "10-override.yml"), "go: 1.22\n")
+	sub := filepath.Join(repo, "sub", "dir")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"import-prefix": "github.com/acme",
+		"lint-preset":   "minimal",
+		"offline":       "true",
+		"go":            "1.22",
+	}
+	for key, value := range want {
+		if got, _ := cfg.Get(key); got != value {
+			t.Errorf("Get(%q) = %q, want %q", key, got, value)
+		}
+	}
+	if got, want := cfg.Source("go"), filepath.Join(repo, RepoDirName, "10-override.yml"); got != want {
+		t.Errorf("Source(go) = %q, want %q", got, want)
+	}
+	if _, ok := cfg.Get("docker"); ok {
+		t.Errorf("docker is not set, but Get returns ok")
+	}
+}
+
+func TestReadAndWriteFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mkgoprj", "config.yaml")
+	values, err := ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 0 {
+		t.Errorf("ReadFile() of not existing file = %v, want empty", values)
+	}
+
+	values["docker"] = "true"
+	if err := WriteFile(file, values); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["docker"] != "true" {
+		t.Errorf("docker = %q, want \"true\"", got["docker"])
+	}
+}
+
+func TestExpandImportPath(t *testing.T) {
+	tests := []struct {
+		prefix     string
+		importPath string
+		want       string
+	}{
+		{prefix: "", importPath: "foo", want: "foo"},
+		{prefix: "github.com/acme", importPath: "foo", want: "github.com/acme/foo"},
+		{prefix: "github.com/acme/", importPath: "foo/v2", want: "github.com/acme/foo/v2"},
+		{prefix: "github.com/acme", importPath: "example.com/foo", want: "example.com/foo"},
+	}
+	for _, tt := range tests {
+		if got := ExpandImportPath(tt.prefix, tt.importPath); got != tt.want {
+			t.Errorf("ExpandImportPath(%q, %q) = %q, want %q", tt.prefix, tt.importPath, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package gittool executes git commands in the repository.
+package gittool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Run execute "$ git <args>" in dir, and returns stdout.
+// If git command fails, the error has stderr of git command.
+func Run(ctx context.Context, dir string, args ...string) (string, error) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		msg := fmt.Sprintf("'git %s' failed: %v", strings.Join(args, " "), err)
+		if out := strings.TrimSpace(stderr.String()); out != "" {
+			msg += "\n" + out
+		}
+		return "", fmt.Errorf("%s", msg)
+	}
+	return stdout.String(), nil
+}
+
+// IsClean reports whether the worktree has no changes and no untracked files.
+func IsClean(ctx context.Context, dir string) (bool, error) {
+	out, err := Run(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "", nil
+}
+
+// Tags returns all tag names in the repository.
+func Tags(ctx context.Context, dir string) ([]string, error) {
+	out, err := Run(ctx, dir, "tag", "--list")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// Commit execute "$ git add -- <files>" and "$ git commit -m <msg> -- <files>".
+// The files that are not tracked yet (e.g. new Changelog.md) are also committed.
+func Commit(ctx context.Context, dir, msg string, files ...string) error {
+	if _, err := Run(ctx, dir, append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	args := append([]string{"commit", "-q", "-m", msg, "--"}, files...)
+	_, err := Run(ctx, dir, args...)
+	return err
+}
+
+// AnnotatedTag execute "$ git tag -a <tag> -m <msg>".
+func AnnotatedTag(ctx context.Context, dir, tag, msg string) error {
+	_, err := Run(ctx, dir, "tag", "-a", tag, "-m", msg)
+	return err
+}
@@ -2,42 +2,219 @@
 package gotool
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
-
-	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
+	"strconv"
+	"strings"
 )
 
-// Version return runtime golang version(only number, not include "go" or "cpu name")
+// versionRex matches go version number in "go1.22.5", "go1.22rc1" or "devel go1.23-xxx".
+var versionRex = regexp.MustCompile(`go(1\.[0-9]+(\.[0-9]+)?)`)
+
+// Version return golang version installed in the system (only number, not include "go" or "cpu name").
+// The value is "$ go env GOVERSION". If it can not execute the go command,
+// return the version of go that built mkgoprj.
 func Version() string {
-	runtimeVer := runtime.Version()
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err == nil {
+		if ver := versionRex.FindStringSubmatch(string(out)); ver != nil {
+			return ver[1]
+		}
+	}
+
+	if ver := versionRex.FindStringSubmatch(runtime.Version()); ver != nil {
+		return ver[1]
+	}
+	return ""
+}
+
+// IsValidVersion reports whether ver is go version that can be written in go.mod (e.g. "1.22" or "1.22.5").
+func IsValidVersion(ver string) bool {
+	return regexp.MustCompile(`^1\.[0-9]+(\.[0-9]+)?$`).MatchString(ver)
+}
+
+// Toolchain returns the toolchain name for the go version (e.g. "1.22" -> "go1.22.0").
+// It returns empty string if the toolchain directive is not needed: the go version does
+// not support it (before go1.21) or it is same as the go directive (e.g. "1.22.5").
+func Toolchain(ver string) string {
+	if !IsValidVersion(ver) {
+		return ""
+	}
+	parts := strings.Split(ver, ".")
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 21 || len(parts) == 3 {
+		return ""
+	}
+	return "go" + ver + ".0"
+}
+
+// Runner executes go command. Stdout and stderr of go command are captured,
+// and the captured output is included in the error if go command fails.
+type Runner struct {
+	// Dir is the working directory of go command. Empty means the current directory.
+	Dir string
+	// Env is the additional environment variables for go command (e.g. "GOPROXY=off").
+	// If the same variable is set twice, the latter is used.
+	Env []string
+	// Verbose is whether to stream the output of go command to Stdout and Stderr.
+	Verbose bool
+	// Stdout is the destination of go command stdout in verbose mode.
+	Stdout io.Writer
+	// Stderr is the destination of go command stderr in verbose mode.
+	Stderr io.Writer
+}
+
+// NewRunner returns Runner that passes GOFLAGS and GOPROXY to go command.
+// Empty goflags or goproxy means the value in the environment is used as it is.
+func NewRunner(goflags, goproxy string, verbose bool) *Runner {
+	env := []string{}
+	if goflags != "" {
+		env = append(env, "GOFLAGS="+goflags)
+	}
+	if goproxy != "" {
+		env = append(env, "GOPROXY="+goproxy)
+	}
+	return &Runner{
+		Env:     env,
+		Verbose: verbose,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}
+}
+
+// WithEnv returns the copy of Runner with additional environment variables.
+func (r *Runner) WithEnv(env ...string) *Runner {
+	newRunner := *r
+	newRunner.Env = append(append([]string{}, r.Env...), env...)
+	return &newRunner
+}
+
+// InDir returns the copy of Runner that executes go command in dir.
+func (r *Runner) InDir(dir string) *Runner {
+	newRunner := *r
+	newRunner.Dir = dir
+	return &newRunner
+}
 
-	rex := regexp.MustCompile("[0-9]\\.[0-9]*")
-	return rex.FindString(runtimeVer)
+// CmdError is the error of go command. It has the output of go command.
+type CmdError struct {
+	Args   []string // arguments of go command
+	Output string   // stdout and stderr of go command
+	Err    error    // error returned by exec.Cmd
+}
+
+// Error returns the go command, the reason and the output of go command.
+func (e *CmdError) Error() string {
+	msg := fmt.Sprintf("'go %s' failed: %v", strings.Join(e.Args, " "), e.Err)
+	if out := strings.TrimSpace(e.Output); out != "" {
+		msg += "\n" + out
+	}
+	return msg
+}
+
+// Unwrap returns the error returned by exec.Cmd.
+func (e *CmdError) Unwrap() error {
+	return e.Err
+}
+
+// Run execute "$ go <args>". If ctx is done before go command finishes,
+// go command is killed and the error has ctx.Err().
+func (r *Runner) Run(ctx context.Context, args ...string) error {
+	output := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), r.Env...)
+	if r.Verbose {
+		cmd.Stdout = io.MultiWriter(output, r.Stdout)
+		cmd.Stderr = io.MultiWriter(output, r.Stderr)
+	} else {
+		cmd.Stdout = output
+		cmd.Stderr = output
+	}
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if errors.Is(ctxErr, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out: %w", ctxErr)
+			} else {
+				err = ctxErr
+			}
+		}
+		return &CmdError{Args: args, Output: output.String(), Err: err}
+	}
+	return nil
 }
 
 // ModInit execute "$ go mod init <importPath>"
-// If it can not execute "$ go mod", exit command.
-func ModInit(importPath string) {
-	if err := exec.Command("go", "mod", "init", importPath).Run(); err != nil {
-		ioutils.Die(err.Error())
+func (r *Runner) ModInit(ctx context.Context, importPath string) error {
+	return r.Run(ctx, "mod", "init", importPath)
+}
+
+// ModEditGoVersion execute "$ go mod edit -go=<ver> -toolchain=<toolchain>"
+// The toolchain directive is not written if it is not needed.
+func (r *Runner) ModEditGoVersion(ctx context.Context, ver string) error {
+	return r.Run(ctx, ModEditGoVersionArgs(ver)...)
+}
+
+// ModEditGoVersionArgs returns the arguments of go command executed by ModEditGoVersion.
+func ModEditGoVersionArgs(ver string) []string {
+	args := []string{"mod", "edit", "-go=" + ver}
+	if toolchain := Toolchain(ver); toolchain != "" {
+		args = append(args, "-toolchain="+toolchain)
 	}
+	return args
+}
+
+// Get execute "$ go get <module@version>..."
+func (r *Runner) Get(ctx context.Context, modules []string) error {
+	return r.Run(ctx, append([]string{"get"}, modules...)...)
 }
 
 // ModTidy execute "$ go mod tidy"
-// If it can not execute "$ go mod", exit command.
-func ModTidy() {
-	if err := exec.Command("go", "mod", "tidy").Run(); err != nil {
-		ioutils.Die(err.Error())
+func (r *Runner) ModTidy(ctx context.Context) error {
+	return r.Run(ctx, "mod", "tidy")
+}
+
+// ModVendor execute "$ go mod vendor"
+func (r *Runner) ModVendor(ctx context.Context) error {
+	return r.Run(ctx, "mod", "vendor")
+}
+
+// Build execute "$ go build ./..."
+func (r *Runner) Build(ctx context.Context) error {
+	return r.Run(ctx, "build", "./...")
+}
+
+// OfflineEnv returns environment variables that prevent go command from accessing network.
+// If mirror is not empty, modules are downloaded from the mirror directory (GOPROXY=file://<mirror>).
+// Otherwise, only modules in the local module cache are used.
+// go.mod and go.sum are not updated (-mod=readonly), so the go command fails if they are stale.
+func OfflineEnv(mirror string) []string {
+	proxy := "off"
+	if mirror != "" {
+		// file URL needs three slashes also on Windows (file:///C:/mirror).
+		path := filepath.ToSlash(mirror)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		proxy = "file://" + path
 	}
+	return []string{"GOPROXY=" + proxy, "GOSUMDB=off", "GOTOOLCHAIN=local", "GOFLAGS=-mod=readonly"}
 }
 
 // CanUseGoCmd check whether go command install in the system.
-// If not install, exit command.
-func CanUseGoCmd() {
-	_, err := exec.LookPath("go")
-	if err != nil {
-		ioutils.Die("this system does not install go cmd. Please download golang")
+// If not install, return error.
+func CanUseGoCmd() error {
+	if _, err := exec.LookPath("go"); err != nil {
+		return errors.New("this system does not install go cmd. Please download golang")
 	}
+	return nil
 }
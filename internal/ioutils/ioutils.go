@@ -2,19 +2,15 @@
 package ioutils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-
-	"github.com/fatih/color"
-	"github.com/mattn/go-colorable"
-)
-
-var (
-	// Stdout is new instance of Writer which handles escape sequence for stdout.
-	Stdout = colorable.NewColorableStdout()
-	// Stderr is new instance of Writer which handles escape sequence for stderr.
-	Stderr = colorable.NewColorableStderr()
+	"sort"
+	"strings"
+	"sync"
 )
 
 // CmdName is this command name.
@@ -26,50 +22,175 @@ func Exists(path string) bool {
 	return (err == nil)
 }
 
-// WriteFile write string to file.
-// If it can not create file, exit command.
-func WriteFile(text string, path string) {
-	file, err := os.Create(path)
+// DefaultFileMode is the permission of the file that does not have the explicit permission.
+const DefaultFileMode os.FileMode = 0644
+
+// WriteFile write string to file with the permission mode. The permission is
+// set after the file is created, so that umask does not change it.
+func WriteFile(text string, path string, mode os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
 	if err != nil {
-		Die(err.Error())
+		return err
+	}
+	if _, err := file.Write([]byte(text)); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteFiles writes files (key=file path, value=text in file) with workers goroutines.
+// The permission of the file is modes[path], or DefaultFileMode if modes does not have it.
+// The parent directories must exist. It stops writing when one of the files can not be
+// written or ctx is done. If some files fail, it returns the error of the first file in sorted order.
+func WriteFiles(ctx context.Context, files map[string]string, modes map[string]os.FileMode, workers int) error {
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
 	}
-	defer file.Close()
-	file.Write(([]byte)(text))
+	sort.Strings(paths)
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				mode, ok := modes[paths[i]]
+				if !ok {
+					mode = DefaultFileMode
+				}
+				if err := WriteFile(files[paths[i]], paths[i], mode); err != nil {
+					errs[i] = err
+					cancel()
+				}
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// The files skipped after the failure have context.Canceled, so the actual failure is preferred.
+	var first error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
 }
 
 // MkDirs create multiple specified directories.
 // If the parent directory does not exist, create the parent directory as well.
-// If an error occurs, exit command.
-func MkDirs(paths []string) {
+// It returns the directories that did not exist before, parents first.
+func MkDirs(paths []string) ([]string, error) {
+	created := []string{}
 	for _, path := range paths {
 		target := os.ExpandEnv(path)
-		err := os.MkdirAll(target, 0755)
-		if err != nil {
-			Die(err.Error())
+		created = append(created, missingDirs(target)...)
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return created, err
 		}
 	}
+	return created, nil
 }
 
-// Warn print warning message
-func Warn(msg string) {
-	fmt.Fprintf(Stderr, "[%s] %s: %s\n", color.YellowString("WARN "), CmdName, msg)
-	os.Exit(1)
+// missingDirs returns path and its parent directories that do not exist, parents first.
+func missingDirs(path string) []string {
+	dirs := []string{}
+	for !Exists(path) {
+		dirs = append([]string{path}, dirs...)
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return dirs
 }
 
-// Die exit program with message.
-func Die(msg string) {
-	fmt.Fprintf(Stderr, "[%s] %s: %s\n", color.RedString("ERROR"), CmdName, msg)
-	os.Exit(1)
+// Tree writes directories in the tree structure to w.
+func Tree(w io.Writer, path string) error {
+	return tree(w, "        ", path)
 }
 
-// Tree display directories in the tree structure.
-func Tree(path string) {
-	if err := tree("        ", path); err != nil {
-		Die("can not print directory-tree: " + err.Error())
+// PlanTree writes the files that are not created yet in the tree structure to w.
+// files are relative paths, and the directories are derived from them.
+func PlanTree(w io.Writer, files []string) {
+	root := planNode{}
+	for _, v := range files {
+		node := root
+		for _, elem := range strings.Split(filepath.ToSlash(v), "/") {
+			if node[elem] == nil {
+				node[elem] = planNode{}
+			}
+			node = node[elem]
+		}
+	}
+	planTree(w, "        ", root)
+}
+
+// planNode is the directory in PlanTree. key=file or directory name, value=children.
+type planNode map[string]planNode
+
+func planTree(w io.Writer, indent string, node planNode) {
+	dirs, files := []string{}, []string{}
+	for k, v := range node {
+		if len(v) == 0 {
+			files = append(files, k)
+		} else {
+			dirs = append(dirs, k)
+		}
+	}
+	sort.Strings(dirs)
+	sort.Strings(files)
+
+	for i, v := range files {
+		s := indent + " ├─"
+		if len(dirs) == 0 && i == len(files)-1 {
+			s = indent + " └─"
+		}
+		fmt.Fprintf(w, "%s %s\n", s, v)
+	}
+
+	for i, v := range dirs {
+		s := indent + " ├─"
+		a := " │ "
+		if i == len(dirs)-1 {
+			s = indent + " └─"
+			a = "   "
+		}
+		fmt.Fprintf(w, "%s %s\n", s, v)
+		planTree(w, indent+a, node[v])
 	}
 }
 
-func tree(indent, path string) error {
+func tree(w io.Writer, indent, path string) error {
 	dirs, files, err := readDirs(path)
 	if err != nil {
 		return err
@@ -81,7 +202,7 @@ func tree(indent, path string) error {
 			s = indent + " └─"
 		}
 
-		fmt.Printf("%s %s\n", s, v)
+		fmt.Fprintf(w, "%s %s\n", s, v)
 	}
 
 	for i, v := range dirs {
@@ -91,9 +212,9 @@ func tree(indent, path string) error {
 			s = indent + " └─"
 			a = "   "
 		}
-		fmt.Printf("%s %s\n", s, v)
+		fmt.Fprintf(w, "%s %s\n", s, v)
 
-		if err := tree(indent+a, filepath.Join(path, v)); err != nil {
+		if err := tree(w, indent+a, filepath.Join(path, v)); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,100 @@
+package ioutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{}
+	for i := 0; i < 100; i++ {
+		files[filepath.Join(dir, fmt.Sprintf("file%03d.go", i))] = fmt.Sprintf("package sample // %d\n", i)
+	}
+	script := filepath.Join(dir, "script.sh")
+	files[script] = "#!/bin/sh\n"
+	modes := map[string]os.FileMode{script: 0755}
+
+	if err := WriteFiles(context.Background(), files, modes, 8); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range files {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantMode := DefaultFileMode
+		if path == script {
+			wantMode = 0755
+		}
+		if stat.Mode().Perm() != wantMode {
+			t.Errorf("mode of %s = %v, want %v", path, stat.Mode().Perm(), wantMode)
+		}
+	}
+}
+
+func TestWriteFilesError(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join(dir, "a.txt"):             "a",
+		filepath.Join(dir, "no_such_dir/b.txt"): "b",
+		filepath.Join(dir, "no_such_dir/c.txt"): "c",
+		filepath.Join(dir, "d.txt"):             "d",
+	}
+
+	err := WriteFiles(context.Background(), files, nil, 4)
+	if err == nil {
+		t.Fatal("WriteFiles() does not return error")
+	}
+	if !strings.Contains(err.Error(), "b.txt") {
+		t.Errorf("WriteFiles() = %v, want the error of the first file in sorted order (b.txt)", err)
+	}
+}
+
+func TestWriteFilesCanceled(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := WriteFiles(ctx, map[string]string{filepath.Join(dir, "a.txt"): "a"}, nil, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WriteFiles() = %v, want context.Canceled", err)
+	}
+	if Exists(filepath.Join(dir, "a.txt")) {
+		t.Errorf("a.txt is written after ctx is canceled")
+	}
+}
+
+func BenchmarkWriteFiles(b *testing.B) {
+	text := strings.Repeat("// generated by mkgoprj\n", 200)
+	for _, workers := range []int{1, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				dir := b.TempDir()
+				files := map[string]string{}
+				for j := 0; j < 500; j++ {
+					files[filepath.Join(dir, fmt.Sprintf("file%03d.go", j))] = text
+				}
+				b.StartTimer()
+
+				if err := WriteFiles(context.Background(), files, nil, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,13 +1,18 @@
+// Package print asks questions to the user in the terminal.
+// Messages that are not questions are displayed by the report package.
 package print
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mattn/go-colorable"
-	"github.com/nao1215/mkgoprj/v2/internal/cmdinfo"
 )
 
 var (
@@ -17,53 +22,118 @@ var (
 	Stderr = colorable.NewColorableStderr()
 )
 
-// Info print information message at STDOUT.
-func Info(msg string) {
-	fmt.Fprintf(Stdout, "%s:%s: %s\n",
-		cmdinfo.Name, color.GreenString("INFO "), msg)
-}
+// Question displays the question in the terminal and receives an answer from the user.
+func Question(ask string) bool {
+	for {
+		fmt.Fprintf(Stdout, "[%s] %s", color.GreenString("CHECK"), ask+" [Y/n] ")
+		response, err := readLine()
+		if err != nil {
+			fmt.Fprintln(Stderr, "")
+			return false
+		}
 
-// Warn print warning message at STDERR.
-func Warn(err interface{}) {
-	fmt.Fprintf(Stderr, "%s:%s: %v\n",
-		cmdinfo.Name, color.YellowString("WARN "), err)
+		switch strings.ToLower(response) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
 }
 
-// Err print error message at STDERR.
-func Err(err interface{}) {
-	fmt.Fprintf(Stderr, "%s:%s: %v\n",
-		cmdinfo.Name, color.HiYellowString("ERROR"), err)
+// Confirm displays the yes/no question in the terminal and receives an answer from the user.
+// Only enter means def. If it can not read the answer, it returns def.
+func Confirm(ask string, def bool) bool {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	for {
+		fmt.Fprintf(Stdout, "[%s] %s %s ", color.GreenString("CHECK"), ask, hint)
+		response, err := readLine()
+		if err != nil {
+			fmt.Fprintln(Stderr, "")
+			return def
+		}
+
+		switch strings.ToLower(response) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
 }
 
-// Fatal print dying message at STDERR.
-func Fatal(err interface{}) {
-	fmt.Fprintf(Stderr, "%s:%s: %v\n",
-		cmdinfo.Name, color.RedString("FATAL"), err)
-	os.Exit(1)
+// Choice displays the choices in the terminal and receives the selected one from the user.
+// The user can answer with the choice or its number. Only enter means def.
+// If it can not read the answer, it returns error.
+func Choice(ask string, choices []string, def string) (string, error) {
+	for {
+		fmt.Fprintf(Stdout, "[%s] %s\n", color.GreenString("CHECK"), ask)
+		for i, v := range choices {
+			mark := " "
+			if v == def {
+				mark = "*"
+			}
+			fmt.Fprintf(Stdout, "  %s %d) %s\n", mark, i+1, v)
+		}
+		fmt.Fprintf(Stdout, "  [%s] ", def)
+		response, err := readLine()
+		if err != nil {
+			return "", err
+		}
+
+		if response == "" {
+			return def, nil
+		}
+		for i, v := range choices {
+			if response == v || response == strconv.Itoa(i+1) {
+				return v, nil
+			}
+		}
+		fmt.Fprintf(Stderr, "  '%s' is not in the choices\n", response)
+	}
 }
 
-// Question displays the question in the terminal and receives an answer from the user.
-func Question(ask string) bool {
-	var response string
+// Input displays the question in the terminal and receives free text from the user.
+// Only enter means def. If validate returns error, ask again.
+// If it can not read the answer, it returns error.
+func Input(ask, def string, validate func(string) error) (string, error) {
+	for {
+		fmt.Fprintf(Stdout, "[%s] %s", color.GreenString("CHECK"), ask)
+		if def != "" {
+			fmt.Fprintf(Stdout, " [%s]", def)
+		}
+		fmt.Fprint(Stdout, " ")
+		response, err := readLine()
+		if err != nil {
+			return "", err
+		}
 
-	fmt.Fprintf(Stdout, "%s:%s: %s",
-		cmdinfo.Name, color.GreenString("CHECK"), ask+" [Y/n] ")
-	_, err := fmt.Scanln(&response)
-	if err != nil {
-		// If user input only enter.
-		if strings.Contains(err.Error(), "expected newline") {
-			return Question(ask)
+		if response == "" {
+			response = def
 		}
-		fmt.Fprint(os.Stderr, err.Error())
-		return false
+		if validate != nil {
+			if err := validate(response); err != nil {
+				fmt.Fprintf(Stderr, "  %v\n", err)
+				continue
+			}
+		}
+		return response, nil
 	}
+}
+
+// stdin is shared by all questions so that buffered answers are not lost.
+var stdin = bufio.NewReader(os.Stdin)
 
-	switch strings.ToLower(response) {
-	case "y", "yes":
-		return true
-	case "n", "no":
-		return false
-	default:
-		return Question(ask)
+// readLine reads one line from STDIN without the line break and spaces around it.
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
 	}
+	return strings.TrimSpace(line), nil
 }
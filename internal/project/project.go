@@ -2,175 +2,441 @@
 package project
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/nao1215/mkgoprj/v2/internal/gotool"
 	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
 	"github.com/nao1215/mkgoprj/v2/internal/target"
+	"golang.org/x/mod/module"
 )
 
+// Option is the optional setting for generating the project.
 type Option struct {
+	target.Option
+	Dir          string          // project root directory. Empty means the project name
+	Output       string          // directory where the project is generated. Empty means the current directory
+	Vendor       bool            // whether to populate vendor directory (only offline mode)
+	ModuleMirror string          // directory used as GOPROXY=file:// in offline mode. Empty means the local module cache
+	GoFlags      string          // GOFLAGS passed to go command. Empty means the value in the environment
+	GoProxy      string          // GOPROXY passed to go command. Empty means the value in the environment
+	Timeout      time.Duration   // time limit of all go commands. Zero means no limit
+	Verbose      bool            // whether to stream the output of go command and show debug messages
+	Verify       bool            // whether to build, vet and test the generated project
+	KeepPartial  bool            // whether to keep the created files when mkgoprj fails or is interrupted
+	Reporter     report.Reporter // output of the progress and the result. nil means text output for humans
 }
 
 // Project have project information to be generated.
 type Project struct {
-	importPath string            // same as "$ git mod init <importPath>"
-	name       string            // project (command) name
-	library    bool              // it mean library project
-	cli        bool              // it means cli project with cobra
-	noRoot     bool              // whether create project root directory or not
-	files      map[string]string // File to be created: key=file path, value=text in file
-	dirs       []string          // directory to be created
+	importPath string                 // same as "$ git mod init <importPath>"
+	name       string                 // project name
+	dir        string                 // project root directory
+	root       string                 // project root directory path (output directory + project root directory)
+	library    bool                   // it mean library project
+	cli        bool                   // it means cli project with cobra
+	noRoot     bool                   // whether create project root directory or not
+	files      map[string]string      // File to be created: key=file path, value=text in file
+	modes      map[string]os.FileMode // permission of the file to be created: key=file path
+	dirs       []string               // directory to be created
+	opt        Option                 // optional setting
+	gocmd      *gotool.Runner         // go command runner
+	report     report.Reporter        // output of the progress and the result
+	created    []string               // files and directories created in this run, in creation order
+	background chan struct{}          // closed when the go command running in background finishes. nil means nothing runs
 }
 
+// writeWorkers is the number of goroutines that write the project files.
+var writeWorkers = runtime.NumCPU()
+
 // NewProject return initialized project struct.
-func NewProject(importPath string, lib, cli, noRoot bool) *Project {
+func NewProject(importPath string, lib, cli, noRoot bool, opt Option) *Project {
 	var prj Project
 	prj.importPath = importPath
-	prj.name = filepath.Base(prj.importPath)
+	prj.name = Name(importPath)
 	prj.library = lib
 	prj.cli = cli
 	prj.noRoot = noRoot
-	prj.files = target.Files(prj.name, importPath, lib, cli, noRoot)
-	prj.dirs = target.Dirs(prj.name, lib, cli, noRoot)
+	prj.dir = opt.Dir
+	if prj.dir == "" {
+		prj.dir = prj.name
+	}
+	if opt.GoVersion == "" {
+		opt.GoVersion = gotool.Version()
+	}
+	if opt.Package == "" {
+		opt.Package = target.PackageName(prj.name)
+	}
+	if opt.BinName == "" {
+		opt.BinName = target.BinaryName(prj.name)
+	}
+	prj.root = filepath.Join(opt.Output, prj.dir)
+	if noRoot {
+		prj.root = filepath.Join(opt.Output, ".")
+	}
+	prj.opt = opt
+	prj.report = opt.Reporter
+	if prj.report == nil {
+		prj.report = report.NewText(report.Options{Verbose: opt.Verbose})
+	}
+	prj.gocmd = gotool.NewRunner(opt.GoFlags, opt.GoProxy, opt.Verbose).InDir(prj.root)
+	prj.gocmd.Stdout, prj.gocmd.Stderr = prj.report.CommandOutput()
+
+	// Paths in the template are relative to the output directory.
+	prj.files = map[string]string{}
+	prj.modes = map[string]os.FileMode{}
+	files, modes := target.Files(prj.dir, importPath, lib, cli, noRoot, opt.Option)
+	for file, code := range files {
+		prj.files[filepath.Join(opt.Output, file)] = code
+		prj.modes[filepath.Join(opt.Output, file)] = modes[file]
+	}
+	for _, dir := range target.Dirs(prj.dir, lib, cli, noRoot, opt.Option) {
+		prj.dirs = append(prj.dirs, filepath.Join(opt.Output, dir))
+	}
 	return &prj
 }
 
-// Make generate project directory and files.
-func (p *Project) Make() {
-	now := time.Now()
+// Name returns the project name derived from the import path. It is the last element
+// of the import path, but the major version suffix is skipped
+// (e.g. "github.com/nao1215/sample/v2" -> "sample", "gopkg.in/yaml.v3" -> "yaml").
+func Name(importPath string) string {
+	prefix, _, ok := module.SplitPathVersion(importPath)
+	if !ok || prefix == "" {
+		prefix = importPath
+	}
+	return path.Base(prefix)
+}
 
-	p.printStartBanner()
-	p.canMake()
-	p.makeProjectDirs()
-	p.makeProjectFiles()
-	p.printDirTree()
-	p.goModInit()
-	if p.cli {
-		p.goModTidy()
+// CheckImportPath returns error if importPath can not be used by "$ go mod init".
+// The first element does not need a dot, so the project name (e.g. "sample") is also valid.
+func CheckImportPath(importPath string) error {
+	if err := module.CheckImportPath(importPath); err != nil {
+		return fmt.Errorf("invalid import path: %w", err)
 	}
+	return nil
+}
 
-	ms := time.Since(now).Milliseconds()
-	p.printEndBanner(ms)
+// PrintPlan displays the files to be created in the tree structure without creating them.
+func (p *Project) PrintPlan() {
+	files := []string{}
+	for k := range p.files {
+		rel, err := filepath.Rel(p.root, k)
+		if err != nil {
+			p.report.Fatal(report.CodeIO, err.Error())
+		}
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	p.report.Plan(p.root, files)
 }
 
-// printStartBanner displays a banner to start creating a project.
-func (p *Project) printStartBanner() {
+// Make generate project directory and files. If ctx is canceled (e.g. by Ctrl-C) or one of
+// the steps fails, the files and directories created by Make are removed unless
+// Option.KeepPartial is set, and exit command.
+func (p *Project) Make(ctx context.Context) {
+	now := time.Now()
+
 	kind := "application"
 	if p.library {
 		kind = "library"
 	}
-	fmt.Printf("%s starts creating the '%s' %s project (import path='%s')\n\n",
-		color.HiYellowString("mkgoprj"), color.GreenString(p.name), kind,
-		color.GreenString(p.importPath))
+	p.report.Begin(p.name, kind, p.importPath)
+	p.report.Debug("project root: " + p.root)
+	p.canMake()
+	p.makeProjectDirs(ctx)
+
+	// go command creates these files in addition to the project template.
+	p.trackNew(filepath.Join(p.root, "go.mod"), filepath.Join(p.root, "go.sum"), filepath.Join(p.root, "vendor"))
+	goCtx := ctx
+	if p.opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		goCtx, cancel = context.WithTimeout(ctx, p.opt.Timeout)
+		defer cancel()
+	}
+
+	// "$ go mod init" only writes go.mod, so it runs while the template files are written.
+	// In offline mode, go.mod is the template file. In verbose mode, the go command output
+	// would be mixed with the progress of creating files.
+	goModInit := p.goModInit
+	if !p.opt.Offline && !p.opt.Verbose {
+		goModInit = p.startGoModInit(goCtx)
+	}
+	p.makeProjectFiles(ctx)
+	p.report.Tree(p.root)
+
+	if p.opt.Offline {
+		if p.opt.Vendor {
+			p.goModVendor(goCtx)
+		}
+		p.goBuildOffline(goCtx)
+	} else {
+		goModInit(goCtx)
+		if p.cli {
+			p.goGet(goCtx)
+			p.goModTidy(goCtx)
+		}
+	}
+	if p.opt.Verify {
+		p.verify(goCtx)
+	}
+
+	p.report.End(time.Since(now))
+}
+
+// runStep reports the step and executes f. If f returns error, remove the created files
+// and exit command with code. If ctx is canceled, exit command with report.CodeCanceled.
+func (p *Project) runStep(ctx context.Context, step report.Step, code report.Code, f func() error) {
+	if ctx.Err() != nil {
+		p.fail(report.CodeCanceled, "interrupted before step '"+step.Name+"'")
+	}
+
+	now := time.Now()
+	p.report.StepStarted(step)
+	if err := f(); err != nil {
+		if errors.Is(err, context.Canceled) {
+			p.fail(report.CodeCanceled, "interrupted during step '"+step.Name+"'")
+		}
+		p.fail(code, err.Error())
+	}
+	p.report.StepFinished(step, time.Since(now))
+}
+
+// fail removes the files and directories created in this run unless --keep-partial,
+// and exit command with code.
+func (p *Project) fail(code report.Code, msg string) {
+	p.cleanup()
+	p.report.Fatal(code, msg)
 }
 
-// printEndBanner displays a banner to end creating a project.
-func (p *Project) printEndBanner(ms int64) {
-	fmt.Println("")
-	fmt.Printf("%s in %d[ms]\n", color.GreenString("BUILD SUCCESSFUL"), ms)
+// trackNew records the paths that do not exist yet as the paths created in this run.
+func (p *Project) trackNew(paths ...string) {
+	for _, v := range paths {
+		if !ioutils.Exists(v) {
+			p.created = append(p.created, v)
+		}
+	}
 }
 
-func (p *Project) printDirTree() {
-	projectDir := p.name
-	if p.noRoot {
-		projectDir = "."
+// cleanup removes the files and directories created in this run in reverse order.
+// If go command runs in background, it waits for the go command before removing files.
+func (p *Project) cleanup() {
+	if p.background != nil {
+		<-p.background
+	}
+	if len(p.created) == 0 {
+		return
+	}
+	if p.opt.KeepPartial {
+		p.report.Warn("keep the partially created project in " + p.root + " (--keep-partial)")
+		return
 	}
 
-	fmt.Printf("        %s (your project root)\n", color.YellowString(projectDir))
-	ioutils.Tree(projectDir)
+	removed := 0
+	for i := len(p.created) - 1; i >= 0; i-- {
+		path := p.created[i]
+		if !ioutils.Exists(path) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			p.report.Warn("can not remove " + path + ": " + err.Error())
+			continue
+		}
+		removed++
+	}
+	p.report.Warn(fmt.Sprintf("removed %d files and directories created by %s (use --keep-partial to keep them)",
+		removed, ioutils.CmdName))
 }
 
 // canMake check whether can create project template or not.
 // If it can't create the project, exit command.
 func (p *Project) canMake() {
-	fmt.Printf("[%s] check if %s can create the project\n",
-		color.GreenString("START"), ioutils.CmdName)
-
-	gotool.CanUseGoCmd()
-	if strings.Trim(p.name, " ") == "" {
-		ioutils.Die("project name is empty (import path end with \"/ \"?)")
+	now := time.Now()
+	step := report.Step{Name: "check", Description: "check if " + ioutils.CmdName + " can create the project"}
+	p.report.StepStarted(step)
+	if err := gotool.CanUseGoCmd(); err != nil {
+		p.report.Fatal(report.CodeGoNotFound, err.Error())
+	}
+	if err := CheckImportPath(p.importPath); err != nil {
+		p.report.Fatal(report.CodeInvalidArgument, err.Error())
 	}
-	p.canMakePrjFile()
+	if err := p.canMakePrjFile(); err != nil {
+		p.report.Fatal(report.CodeAlreadyExists, err.Error())
+	}
+	p.report.StepFinished(step, time.Since(now))
 }
 
 // makeProjectDirs create all directories in project template.
 // If it can not make directories, exit command.
-func (p *Project) makeProjectDirs() {
-	fmt.Printf("[%s] create directories\n", color.GreenString("START"))
-	ioutils.MkDirs(p.dirs)
+func (p *Project) makeProjectDirs(ctx context.Context) {
+	step := report.Step{Name: "create-directories", Description: "create directories"}
+	p.runStep(ctx, step, report.CodeIO, func() error {
+		created, err := ioutils.MkDirs(p.dirs)
+		p.created = append(p.created, created...)
+		return err
+	})
 }
 
-// makeProjectDirs create all files in application project template.
-func (p *Project) makeProjectFiles() {
-	fmt.Printf("[%s] create files\n", color.GreenString("START"))
-	for path, code := range p.files {
-		ioutils.WriteFile(code, path)
-	}
+// makeProjectFiles create all files in project template with writeWorkers goroutines.
+// The created files are reported in sorted order after all files are written.
+// If it can not make files, exit command.
+func (p *Project) makeProjectFiles(ctx context.Context) {
+	step := report.Step{Name: "create-files", Description: "create files"}
+	p.runStep(ctx, step, report.CodeIO, func() error {
+		paths := []string{}
+		for path := range p.files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		// All files are tracked before writing because the files are written in any order.
+		// cleanup skips the files that are not created.
+		p.created = append(p.created, paths...)
+		if err := ioutils.WriteFiles(ctx, p.files, p.modes, writeWorkers); err != nil {
+			return err
+		}
+		for _, path := range paths {
+			p.report.FileCreated(path, len(p.files[path]), p.modes[path])
+		}
+		return nil
+	})
 }
 
 // canMakePrjFile check whether the file mkgoprj is trying to generate already exists.
-func (p *Project) canMakePrjFile() {
+func (p *Project) canMakePrjFile() error {
 	var files []string
 	for k := range p.files {
 		files = append(files, k)
 	}
 	files = append(files, p.dirs...)
+	sort.Strings(files)
 
 	for _, v := range files {
 		if ioutils.Exists(v) {
-			ioutils.Die("same name file (" + v + ") already exists")
+			return errors.New("same name file (" + v + ") already exists")
 		}
 	}
+	return nil
 }
 
-// goModInit execute "$ go mod init <importPath>"
-// If it can not execute "$ go mod", exit command.
-func (p *Project) goModInit() {
-	fmt.Printf("[%s] Execute 'go mod init %s'\n", color.GreenString("START"), p.importPath)
-	preDir, err := os.Getwd()
-	if err != nil {
-		ioutils.Die(err.Error())
+// goStep returns the step that executes go command with args.
+func goStep(name string, args []string, suffix string) report.Step {
+	desc := "Execute 'go " + strings.Join(args, " ") + "'"
+	if suffix != "" {
+		desc += " (" + suffix + ")"
 	}
+	return report.Step{Name: name, Description: desc}
+}
 
-	if !p.noRoot {
-		err := os.Chdir(p.name)
-		if err != nil {
-			ioutils.Die(err.Error())
+// goModInit execute "$ go mod init <importPath>" and pin go version with "$ go mod edit".
+// If it can not execute "$ go mod", exit command.
+func (p *Project) goModInit(ctx context.Context) {
+	p.runStep(ctx, goStep("go-mod-init", []string{"mod", "init", p.importPath}, ""), report.CodeGoCommand,
+		func() error { return p.gocmd.ModInit(ctx, p.importPath) })
+	p.runStep(ctx, goStep("go-mod-edit", gotool.ModEditGoVersionArgs(p.opt.GoVersion), ""), report.CodeGoCommand,
+		func() error { return p.gocmd.ModEditGoVersion(ctx, p.opt.GoVersion) })
+}
+
+// startGoModInit starts the go commands of goModInit in background, and returns the function
+// that waits for them and reports the steps in the same order as goModInit.
+func (p *Project) startGoModInit(ctx context.Context) func(context.Context) {
+	var initErr, editErr error
+	p.background = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		if initErr = p.gocmd.ModInit(ctx, p.importPath); initErr != nil {
+			return
 		}
+		editErr = p.gocmd.ModEditGoVersion(ctx, p.opt.GoVersion)
+	}(p.background)
+
+	return func(ctx context.Context) {
+		p.runStep(ctx, goStep("go-mod-init", []string{"mod", "init", p.importPath}, ""), report.CodeGoCommand,
+			func() error {
+				<-p.background
+				return initErr
+			})
+		p.runStep(ctx, goStep("go-mod-edit", gotool.ModEditGoVersionArgs(p.opt.GoVersion), ""), report.CodeGoCommand,
+			func() error { return editErr })
 	}
-	gotool.ModInit(p.importPath)
+}
 
-	err = os.Chdir(preDir)
-	if err != nil {
-		ioutils.Die(err.Error())
+// goGet execute "$ go get <module@version>..." for the dependencies pinned by
+// the project template, so that "$ go mod tidy" does not choose the latest version.
+// If it can not execute "$ go get", exit command.
+func (p *Project) goGet(ctx context.Context) {
+	modules := []string{}
+	for _, v := range target.DirectDependencies(p.library, p.cli) {
+		modules = append(modules, v.Path+"@"+v.Version)
 	}
+	if len(modules) == 0 {
+		return
+	}
+
+	p.runStep(ctx, goStep("go-get", append([]string{"get"}, modules...), ""), report.CodeGoCommand,
+		func() error { return p.gocmd.Get(ctx, modules) })
 }
 
 // goModTidy execute "$ go mod tidy"
 // If it can not execute "$ go mod", exit command.
-func (p *Project) goModTidy() {
-	fmt.Printf("[%s] Execute 'go mod tidy'\n", color.GreenString("START"))
-	preDir, err := os.Getwd()
-	if err != nil {
-		ioutils.Die(err.Error())
+func (p *Project) goModTidy(ctx context.Context) {
+	p.runStep(ctx, goStep("go-mod-tidy", []string{"mod", "tidy"}, ""), report.CodeGoCommand,
+		func() error { return p.gocmd.ModTidy(ctx) })
+}
+
+// goModVendor execute "$ go mod vendor" without network access.
+// If it can not execute "$ go mod", exit command.
+func (p *Project) goModVendor(ctx context.Context) {
+	gocmd := p.gocmd.WithEnv(gotool.OfflineEnv(p.opt.ModuleMirror)...)
+	p.runStep(ctx, goStep("go-mod-vendor", []string{"mod", "vendor"}, "offline"), report.CodeGoCommand,
+		func() error { return gocmd.ModVendor(ctx) })
+}
+
+// goBuildOffline execute "$ go build ./..." without network access to verify that go.mod
+// and go.sum are complete. They are not updated (-mod=readonly, or -mod=vendor with
+// vendor directory), so it fails if they do not match the source code.
+// If the build fails, exit command.
+func (p *Project) goBuildOffline(ctx context.Context) {
+	gocmd := p.gocmd.WithEnv(gotool.OfflineEnv(p.opt.ModuleMirror)...)
+	if p.opt.Vendor {
+		gocmd = gocmd.WithEnv("GOFLAGS=-mod=vendor")
 	}
+	p.runStep(ctx, goStep("go-build-offline", []string{"build", "./..."}, "offline"), report.CodeGoCommand,
+		func() error { return gocmd.Build(ctx) })
+}
 
-	if !p.noRoot {
-		err := os.Chdir(p.name)
-		if err != nil {
-			ioutils.Die(err.Error())
-		}
+// verify execute "$ go build ./...", "$ go vet ./..." and "$ go test ./..."
+// in the generated project to check that the project template is not broken.
+// If one of them fails, exit command.
+func (p *Project) verify(ctx context.Context) {
+	gocmd := p.gocmd
+	if p.opt.Offline {
+		gocmd = gocmd.WithEnv(gotool.OfflineEnv(p.opt.ModuleMirror)...)
 	}
-	gotool.ModTidy()
 
-	err = os.Chdir(preDir)
-	if err != nil {
-		ioutils.Die(err.Error())
+	steps := [][]string{
+		{"build", "./..."},
+		{"vet", "./..."},
+		{"test", "./..."},
+	}
+	for _, args := range steps {
+		args := args
+		step := goStep("verify-go-"+args[0], args, "verify")
+		step.Result = "'go " + strings.Join(args, " ") + "' (verify)"
+		p.runStep(ctx, step, report.CodeVerification, func() error {
+			if err := gocmd.Run(ctx, args...); err != nil {
+				return fmt.Errorf("the generated project is broken. '%s' failed in verification. "+
+					"Please report this issue to mkgoprj developers\n%w", "go "+strings.Join(args, " "), err)
+			}
+			return nil
+		})
 	}
 }
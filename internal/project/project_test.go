@@ -0,0 +1,93 @@
+package project
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
+	"github.com/nao1215/mkgoprj/v2/internal/report"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{importPath: "github.com/nao1215/sample", want: "sample"},
+		{importPath: "github.com/nao1215/sample/v2", want: "sample"},
+		{importPath: "github.com/nao1215/v2", want: "nao1215"},
+		{importPath: "gopkg.in/yaml.v3", want: "yaml"},
+		{importPath: "example.com/my-lib", want: "my-lib"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.importPath); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.importPath, got, tt.want)
+		}
+	}
+}
+
+func TestCheckImportPath(t *testing.T) {
+	tests := []struct {
+		importPath string
+		wantErr    bool
+	}{
+		{importPath: "github.com/nao1215/sample"},
+		{importPath: "github.com/nao1215/sample/v2"},
+		{importPath: "sample"},
+		{importPath: "acme/sample"},
+		{importPath: "", wantErr: true},
+		{importPath: "github.com/nao1215/sample/", wantErr: true},
+		{importPath: "github.com/nao1215/../sample", wantErr: true},
+		{importPath: "github.com/nao1215/my sample", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckImportPath(tt.importPath); (err != nil) != tt.wantErr {
+			t.Errorf("CheckImportPath(%q) = %v, wantErr %v", tt.importPath, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepPartial bool
+	}{
+		{name: "remove created files", keepPartial: false},
+		{name: "keep partial", keepPartial: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := t.TempDir()
+			existing := filepath.Join(output, "existing.txt")
+			if err := ioutils.WriteFile("keep", existing, ioutils.DefaultFileMode); err != nil {
+				t.Fatal(err)
+			}
+
+			opt := Option{Reporter: report.NewJSON(io.Discard, report.Options{}), KeepPartial: tt.keepPartial}
+			opt.Output = filepath.Join(output, "out")
+			opt.GoVersion = "1.22"
+			p := NewProject("github.com/nao1215/sample", true, false, false, opt)
+			p.makeProjectDirs(context.Background())
+			p.makeProjectFiles(context.Background())
+			p.trackNew(existing, filepath.Join(p.root, "go.mod"))
+			p.cleanup()
+
+			if !ioutils.Exists(existing) {
+				t.Errorf("%s is removed, but it is not created by mkgoprj", existing)
+			}
+			if got := ioutils.Exists(opt.Output); got != tt.keepPartial {
+				t.Errorf("Exists(%s) = %v, want %v", opt.Output, got, tt.keepPartial)
+			}
+			entries, err := os.ReadDir(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.keepPartial && len(entries) != 1 {
+				t.Errorf("%d entries are left in the output directory, want 1", len(entries))
+			}
+		})
+	}
+}
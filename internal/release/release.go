@@ -0,0 +1,74 @@
+// Package release computes the next semantic version from git tags.
+package release
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// Patch increments the patch version (e.g. v1.2.3 -> v1.2.4).
+	Patch = "patch"
+	// Minor increments the minor version and resets the patch version (e.g. v1.2.3 -> v1.3.0).
+	Minor = "minor"
+	// Major increments the major version and resets the others (e.g. v1.2.3 -> v2.0.0).
+	Major = "major"
+)
+
+// Bumps returns all version bump names.
+func Bumps() []string {
+	return []string{Patch, Minor, Major}
+}
+
+// LatestVersion returns the highest semantic version tag (e.g. "v1.2.3") in tags.
+// Tags that are not semantic version or are prerelease are ignored.
+// It returns empty string if there is no version tag.
+func LatestVersion(tags []string) string {
+	latest := ""
+	for _, v := range tags {
+		if !semver.IsValid(v) || semver.Prerelease(v) != "" || strings.Count(v, ".") != 2 {
+			continue
+		}
+		if latest == "" || semver.Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// NextVersion returns the version after latest. If latest is empty, it is regarded as v0.0.0.
+func NextVersion(latest, bump string) (string, error) {
+	if latest == "" {
+		latest = "v0.0.0"
+	}
+	if !semver.IsValid(latest) {
+		return "", fmt.Errorf("'%s' is not semantic version", latest)
+	}
+
+	// Canonical completes "v1" to "v1.0.0" and removes the build metadata.
+	core := strings.TrimPrefix(semver.Canonical(latest), "v")
+	core = strings.TrimSuffix(core, semver.Prerelease(latest))
+	nums := make([]int, 3)
+	for i, v := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not semantic version", latest)
+		}
+		nums[i] = n
+	}
+
+	switch bump {
+	case Patch:
+		nums[2]++
+	case Minor:
+		nums[1], nums[2] = nums[1]+1, 0
+	case Major:
+		nums[0], nums[1], nums[2] = nums[0]+1, 0, 0
+	default:
+		return "", fmt.Errorf("unknown version bump '%s' (choose from %s)", bump, strings.Join(Bumps(), ", "))
+	}
+	return fmt.Sprintf("v%d.%d.%d", nums[0], nums[1], nums[2]), nil
+}
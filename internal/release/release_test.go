@@ -0,0 +1,44 @@
+package release
+
+import "testing"
+
+func TestLatestVersion(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{tags: nil, want: ""},
+		{tags: []string{"v0.1.0", "v0.10.0", "v0.9.1"}, want: "v0.10.0"},
+		{tags: []string{"v1.0.0", "v2.0.0-rc1", "latest", "v1"}, want: "v1.0.0"},
+		{tags: []string{"1.2.3"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := LatestVersion(tt.tags); got != tt.want {
+			t.Errorf("LatestVersion(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		latest  string
+		bump    string
+		want    string
+		wantErr bool
+	}{
+		{latest: "", bump: Patch, want: "v0.0.1"},
+		{latest: "", bump: Minor, want: "v0.1.0"},
+		{latest: "", bump: Major, want: "v1.0.0"},
+		{latest: "v1.2.3", bump: Patch, want: "v1.2.4"},
+		{latest: "v1.2.3", bump: Minor, want: "v1.3.0"},
+		{latest: "v1.2.3", bump: Major, want: "v2.0.0"},
+		{latest: "v1.2.3", bump: "huge", wantErr: true},
+		{latest: "1.2.3", bump: Patch, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NextVersion(tt.latest, tt.bump)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NextVersion(%q, %q) = %q, %v, want %q (error=%v)", tt.latest, tt.bump, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
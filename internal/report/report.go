@@ -0,0 +1,365 @@
+// Package report outputs the progress and the result of project generation
+// for humans (text) or for programs (JSON events).
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
+	"github.com/nao1215/mkgoprj/v2/internal/ioutils"
+)
+
+const (
+	// FormatText is the output format for humans.
+	FormatText = "text"
+	// FormatJSON is the output format that writes one JSON event per line.
+	FormatJSON = "json"
+)
+
+// Formats returns all output format names.
+func Formats() []string {
+	return []string{FormatText, FormatJSON}
+}
+
+// Code is the error code in the error event.
+type Code string
+
+const (
+	// CodeInvalidArgument means that the command line argument or the config is invalid.
+	CodeInvalidArgument Code = "invalid_argument"
+	// CodeAlreadyExists means that the file to be generated already exists.
+	CodeAlreadyExists Code = "already_exists"
+	// CodeGoNotFound means that go command is not installed.
+	CodeGoNotFound Code = "go_not_found"
+	// CodeGoCommand means that go command (e.g. "$ go mod tidy") failed.
+	CodeGoCommand Code = "go_command_failed"
+	// CodeVerification means that the generated project can not be built, vetted or tested.
+	CodeVerification Code = "verification_failed"
+	// CodeIO means that mkgoprj can not read or write file.
+	CodeIO Code = "io_error"
+	// CodeGitCommand means that git command (e.g. "$ git log") failed.
+	CodeGitCommand Code = "git_command_failed"
+	// CodeFailedPrecondition means that the state of the repository does not allow the command
+	// (e.g. the worktree has uncommitted changes).
+	CodeFailedPrecondition Code = "failed_precondition"
+	// CodeCanceled means that mkgoprj is interrupted by signal (e.g. Ctrl-C).
+	CodeCanceled Code = "canceled"
+)
+
+// exitCodes is the exit status for each error code. Other codes exit with 1.
+var exitCodes = map[Code]int{
+	CodeInvalidArgument:    2,
+	CodeAlreadyExists:      3,
+	CodeGoNotFound:         4,
+	CodeGoCommand:          5,
+	CodeVerification:       6,
+	CodeIO:                 7,
+	CodeGitCommand:         8,
+	CodeFailedPrecondition: 9,
+	CodeCanceled:           130, // same as the shell (128 + SIGINT)
+}
+
+// ExitCode returns the exit status of mkgoprj for the error code.
+func ExitCode(code Code) int {
+	if v, ok := exitCodes[code]; ok {
+		return v
+	}
+	return 1
+}
+
+// Options is the setting of Reporter shared by all output formats.
+type Options struct {
+	Quiet   bool // whether to show only warnings and errors
+	Verbose bool // whether to show debug messages and the elapsed time of each step
+	NoColor bool // whether to disable colored output. NO_COLOR environment variable also disables it
+}
+
+// Step is the unit of work in project generation.
+type Step struct {
+	Name        string // machine readable name (e.g. "go-mod-tidy")
+	Description string // human readable description (e.g. "Execute 'go mod tidy'")
+	Result      string // human readable result shown when the step finishes. Empty means nothing is shown
+}
+
+// Reporter outputs the progress and the result of project generation.
+type Reporter interface {
+	// Begin reports that mkgoprj starts creating the project.
+	Begin(name, kind, importPath string)
+	// StepStarted reports that the step starts.
+	StepStarted(step Step)
+	// StepFinished reports that the step finished successfully.
+	StepFinished(step Step, elapsed time.Duration)
+	// FileCreated reports that the file is created with the permission mode.
+	FileCreated(path string, size int, mode os.FileMode)
+	// Tree reports the directory tree of the created project.
+	Tree(root string)
+	// Plan reports the files that will be created in the project.
+	Plan(root string, files []string)
+	// Info reports the message for the user.
+	Info(msg string)
+	// Debug reports the message that is shown only in verbose mode.
+	Debug(msg string)
+	// Warn reports the problem that does not stop command.
+	Warn(msg string)
+	// Error reports the error and returns the exit status for code.
+	Error(code Code, msg string) int
+	// Fatal reports the error and exits command with the exit status for code.
+	Fatal(code Code, msg string)
+	// End reports that the project is created.
+	End(elapsed time.Duration)
+	// CommandOutput returns the writers for the output of go command in verbose mode.
+	CommandOutput() (stdout, stderr io.Writer)
+}
+
+// New returns Reporter for the output format. If format is unknown, it returns error.
+func New(format string, opt Options) (Reporter, error) {
+	if opt.NoColor {
+		color.NoColor = true
+	}
+	switch format {
+	case FormatText, "":
+		return NewText(opt), nil
+	case FormatJSON:
+		return NewJSON(os.Stdout, opt), nil
+	}
+	return nil, fmt.Errorf("unknown output format '%s' (choose from %s, %s)", format, FormatText, FormatJSON)
+}
+
+// osExit is os.Exit. exit is replaced in test.
+var (
+	osExit = os.Exit
+	exit   = osExit
+)
+
+// Text is Reporter for humans. It writes colored messages and the directory tree.
+type Text struct {
+	stdout io.Writer
+	stderr io.Writer
+	opt    Options
+}
+
+// NewText returns Reporter for humans.
+func NewText(opt Options) *Text {
+	return newText(colorable.NewColorableStdout(), colorable.NewColorableStderr(), opt)
+}
+
+func newText(stdout, stderr io.Writer, opt Options) *Text {
+	return &Text{stdout: stdout, stderr: stderr, opt: opt}
+}
+
+// Begin displays a banner to start creating a project.
+func (t *Text) Begin(name, kind, importPath string) {
+	if t.opt.Quiet {
+		return
+	}
+	fmt.Fprintf(t.stdout, "%s starts creating the '%s' %s project (import path='%s')\n\n",
+		color.HiYellowString("mkgoprj"), color.GreenString(name), kind, color.GreenString(importPath))
+}
+
+// StepStarted displays "[START] <description>".
+func (t *Text) StepStarted(step Step) {
+	if t.opt.Quiet {
+		return
+	}
+	fmt.Fprintf(t.stdout, "[%s] %s\n", color.GreenString("START"), step.Description)
+}
+
+// StepFinished displays "[ OK  ] <result>" if the step has the result to be shown.
+// In verbose mode, it also displays the elapsed time of the step.
+func (t *Text) StepFinished(step Step, elapsed time.Duration) {
+	if t.opt.Quiet {
+		return
+	}
+	if step.Result != "" {
+		fmt.Fprintf(t.stdout, "[%s] %s\n", color.GreenString(" OK  "), step.Result)
+	}
+	t.Debug(fmt.Sprintf("%s finished in %d[ms]", step.Name, elapsed.Milliseconds()))
+}
+
+// FileCreated displays nothing because the created files are shown by Tree.
+func (t *Text) FileCreated(path string, size int, mode os.FileMode) {}
+
+// Tree displays the directory tree of the project root.
+func (t *Text) Tree(root string) {
+	if t.opt.Quiet {
+		return
+	}
+	fmt.Fprintf(t.stdout, "        %s (your project root)\n", color.YellowString(root))
+	if err := ioutils.Tree(t.stdout, root); err != nil {
+		t.Warn("can not print directory-tree: " + err.Error())
+	}
+}
+
+// Plan displays the files that will be created in the tree structure.
+// It is shown even in quiet mode because the user is asked to confirm it.
+func (t *Text) Plan(root string, files []string) {
+	fmt.Fprintf(t.stdout, "        %s (your project root)\n", color.YellowString(root))
+	ioutils.PlanTree(t.stdout, files)
+}
+
+// Info displays "[INFO ] <msg>" at STDOUT.
+func (t *Text) Info(msg string) {
+	if t.opt.Quiet {
+		return
+	}
+	fmt.Fprintf(t.stdout, "[%s] %s\n", color.GreenString("INFO "), msg)
+}
+
+// Debug displays "[DEBUG] <msg>" at STDOUT in verbose mode.
+func (t *Text) Debug(msg string) {
+	if !t.opt.Verbose {
+		return
+	}
+	fmt.Fprintf(t.stdout, "[%s] %s\n", color.CyanString("DEBUG"), msg)
+}
+
+// Warn displays the warning message at STDERR.
+func (t *Text) Warn(msg string) {
+	fmt.Fprintf(t.stderr, "[%s] mkgoprj: %s\n", color.YellowString("WARN "), msg)
+}
+
+// Error displays the error message at STDERR and returns the exit status for code.
+func (t *Text) Error(code Code, msg string) int {
+	fmt.Fprintf(t.stderr, "[%s] mkgoprj: %s\n", color.RedString("ERROR"), msg)
+	return ExitCode(code)
+}
+
+// Fatal displays the error message at STDERR and exits command.
+func (t *Text) Fatal(code Code, msg string) {
+	exit(t.Error(code, msg))
+}
+
+// End displays a banner to end creating a project.
+func (t *Text) End(elapsed time.Duration) {
+	if t.opt.Quiet {
+		return
+	}
+	fmt.Fprintln(t.stdout, "")
+	fmt.Fprintf(t.stdout, "%s in %d[ms]\n", color.GreenString("BUILD SUCCESSFUL"), elapsed.Milliseconds())
+}
+
+// CommandOutput returns STDOUT and STDERR.
+func (t *Text) CommandOutput() (io.Writer, io.Writer) {
+	return os.Stdout, os.Stderr
+}
+
+// Event is the JSON event written by JSON reporter.
+type Event struct {
+	Time       string `json:"time"`                  // RFC3339 time when the event occurs
+	Event      string `json:"event"`                 // event name (e.g. "step_started")
+	Name       string `json:"name,omitempty"`        // project name (begin)
+	Kind       string `json:"kind,omitempty"`        // project kind (begin)
+	ImportPath string `json:"import_path,omitempty"` // import path (begin)
+	Step       string `json:"step,omitempty"`        // step name (step_started, step_finished)
+	DurationMs *int64 `json:"duration_ms,omitempty"` // elapsed time (step_finished, summary)
+	Path       string `json:"path,omitempty"`        // file path (file_created, file_planned)
+	Size       *int   `json:"size,omitempty"`        // file size in bytes (file_created)
+	Mode       string `json:"mode,omitempty"`        // file permission in octal (file_created)
+	Files      *int   `json:"files,omitempty"`       // number of created files (summary)
+	Success    *bool  `json:"success,omitempty"`     // whether the project is created (summary)
+	Code       Code   `json:"code,omitempty"`        // error code (error)
+	Message    string `json:"message,omitempty"`     // message (info, debug, warning, error)
+}
+
+// JSON is Reporter for programs. It writes one JSON event per line and no decoration.
+type JSON struct {
+	w     io.Writer
+	enc   *json.Encoder
+	opt   Options
+	files int
+	now   func() time.Time
+}
+
+// NewJSON returns Reporter that writes JSON events to w.
+func NewJSON(w io.Writer, opt Options) *JSON {
+	return &JSON{w: w, enc: json.NewEncoder(w), opt: opt, now: time.Now}
+}
+
+func (j *JSON) emit(e Event) {
+	e.Time = j.now().Format(time.RFC3339)
+	// Encode error can not be reported anywhere, so it is ignored.
+	_ = j.enc.Encode(e)
+}
+
+// Begin writes "begin" event.
+func (j *JSON) Begin(name, kind, importPath string) {
+	j.emit(Event{Event: "begin", Name: name, Kind: kind, ImportPath: importPath})
+}
+
+// StepStarted writes "step_started" event.
+func (j *JSON) StepStarted(step Step) {
+	j.emit(Event{Event: "step_started", Step: step.Name})
+}
+
+// StepFinished writes "step_finished" event with the duration.
+func (j *JSON) StepFinished(step Step, elapsed time.Duration) {
+	ms := elapsed.Milliseconds()
+	j.emit(Event{Event: "step_finished", Step: step.Name, DurationMs: &ms})
+}
+
+// FileCreated writes "file_created" event with the path, the size and the permission (e.g. "0644").
+func (j *JSON) FileCreated(path string, size int, mode os.FileMode) {
+	j.files++
+	j.emit(Event{Event: "file_created", Path: path, Size: &size, Mode: fmt.Sprintf("%04o", mode.Perm())})
+}
+
+// Tree writes nothing because the created files are reported by "file_created" events.
+func (j *JSON) Tree(root string) {}
+
+// Plan writes "file_planned" event for each file.
+func (j *JSON) Plan(root string, files []string) {
+	for _, v := range files {
+		j.emit(Event{Event: "file_planned", Path: v})
+	}
+}
+
+// Info writes "info" event except in quiet mode.
+func (j *JSON) Info(msg string) {
+	if j.opt.Quiet {
+		return
+	}
+	j.emit(Event{Event: "info", Message: msg})
+}
+
+// Debug writes "debug" event in verbose mode.
+func (j *JSON) Debug(msg string) {
+	if !j.opt.Verbose {
+		return
+	}
+	j.emit(Event{Event: "debug", Message: msg})
+}
+
+// Warn writes "warning" event.
+func (j *JSON) Warn(msg string) {
+	j.emit(Event{Event: "warning", Message: msg})
+}
+
+// Error writes "error" event and failed "summary" event, and returns the exit status for code.
+func (j *JSON) Error(code Code, msg string) int {
+	j.emit(Event{Event: "error", Code: code, Message: msg})
+	success := false
+	j.emit(Event{Event: "summary", Files: &j.files, Success: &success})
+	return ExitCode(code)
+}
+
+// Fatal writes "error" event and failed "summary" event, and exits command.
+func (j *JSON) Fatal(code Code, msg string) {
+	exit(j.Error(code, msg))
+}
+
+// End writes successful "summary" event.
+func (j *JSON) End(elapsed time.Duration) {
+	ms := elapsed.Milliseconds()
+	success := true
+	j.emit(Event{Event: "summary", DurationMs: &ms, Files: &j.files, Success: &success})
+}
+
+// CommandOutput returns STDERR for both, so that STDOUT has only JSON events.
+func (j *JSON) CommandOutput() (io.Writer, io.Writer) {
+	return os.Stderr, os.Stderr
+}
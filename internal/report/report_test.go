@@ -0,0 +1,133 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestJSON(t *testing.T) {
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	t.Cleanup(func() { exit = osExit })
+
+	buf := new(bytes.Buffer)
+	r := NewJSON(buf, Options{})
+	r.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	step := Step{Name: "create-files", Description: "create files"}
+	r.Begin("sample", "library", "github.com/nao1215/sample")
+	r.StepStarted(step)
+	r.FileCreated("sample/sample.go", 42, 0644)
+	r.StepFinished(step, 1500*time.Millisecond)
+	r.Warn("warning message")
+	r.Fatal(CodeGoCommand, "'go mod tidy' failed")
+
+	want := []string{
+		`{"time":"2024-01-02T03:04:05Z","event":"begin","name":"sample","kind":"library","import_path":"github.com/nao1215/sample"}`,
+		`{"time":"2024-01-02T03:04:05Z","event":"step_started","step":"create-files"}`,
+		`{"time":"2024-01-02T03:04:05Z","event":"file_created","path":"sample/sample.go","size":42,"mode":"0644"}`,
+		`{"time":"2024-01-02T03:04:05Z","event":"step_finished","step":"create-files","duration_ms":1500}`,
+		`{"time":"2024-01-02T03:04:05Z","event":"warning","message":"warning message"}`,
+		`{"time":"2024-01-02T03:04:05Z","event":"error","code":"go_command_failed","message":"'go mod tidy' failed"}`,
+		`{"time":"2024-01-02T03:04:05Z","event":"summary","files":1,"success":false}`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d events\n%s", len(got), len(want), buf.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event[%d] = %s, want %s", i, got[i], want[i])
+		}
+		if !json.Valid([]byte(got[i])) {
+			t.Errorf("event[%d] is not valid JSON: %s", i, got[i])
+		}
+	}
+	if exitCode != 5 {
+		t.Errorf("exit code = %d, want 5", exitCode)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name       string
+		opt        Options
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "default",
+			opt:        Options{},
+			wantStdout: "[START] create files\n[INFO ] info message\n",
+			wantStderr: "[WARN ] mkgoprj: warning message\n[ERROR] mkgoprj: error message\n",
+		},
+		{
+			name:       "quiet",
+			opt:        Options{Quiet: true},
+			wantStdout: "",
+			wantStderr: "[WARN ] mkgoprj: warning message\n[ERROR] mkgoprj: error message\n",
+		},
+		{
+			name:       "verbose",
+			opt:        Options{Verbose: true},
+			wantStdout: "[START] create files\n[DEBUG] create-files finished in 3[ms]\n[INFO ] info message\n[DEBUG] debug message\n",
+			wantStderr: "[WARN ] mkgoprj: warning message\n[ERROR] mkgoprj: error message\n",
+		},
+	}
+
+	color.NoColor = true
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			r := newText(stdout, stderr, tt.opt)
+
+			step := Step{Name: "create-files", Description: "create files"}
+			r.StepStarted(step)
+			r.StepFinished(step, 3*time.Millisecond)
+			r.Info("info message")
+			r.Debug("debug message")
+			r.Warn("warning message")
+			if got := r.Error(CodeAlreadyExists, "error message"); got != 3 {
+				t.Errorf("Error() = %d, want 3", got)
+			}
+
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if stderr.String() != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	seen := map[int]Code{0: "", 1: ""}
+	for _, code := range []Code{CodeInvalidArgument, CodeAlreadyExists, CodeGoNotFound,
+		CodeGoCommand, CodeVerification, CodeIO, CodeGitCommand, CodeFailedPrecondition, CodeCanceled} {
+		got := ExitCode(code)
+		if other, ok := seen[got]; ok {
+			t.Errorf("ExitCode(%s) = %d is same as the exit code of '%s'", code, got, other)
+		}
+		seen[got] = code
+	}
+	if got := ExitCode("unknown"); got != 1 {
+		t.Errorf("ExitCode(unknown) = %d, want 1", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, v := range Formats() {
+		if _, err := New(v, Options{}); err != nil {
+			t.Errorf("New(%q) returns error: %v", v, err)
+		}
+	}
+	if _, err := New("xml", Options{}); err == nil {
+		t.Errorf("New(\"xml\") does not return error")
+	}
+}
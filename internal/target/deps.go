@@ -0,0 +1,131 @@
+package target
+
+// Dependency is go module that the project template depends on.
+type Dependency struct {
+	Path       string // module path
+	Version    string // module version pinned by mkgoprj
+	Indirect   bool   // whether the module is indirect dependency
+	RequiredBy string // template file (or module, if indirect) that requires the module
+}
+
+// Tool is the external tool (not go module) that the generated files use.
+type Tool struct {
+	Name       string // tool name or container image
+	Version    string // tool version pinned by mkgoprj
+	RequiredBy string // generated file that uses the tool
+}
+
+// golangciLintVersion is golangci-lint version used by the reviewdog workflow.
+const golangciLintVersion = "v2.1.6"
+
+const (
+	// dockerRuntimeImageName is the container image of Dockerfile runtime stage.
+	dockerRuntimeImageName = "gcr.io/distroless/static-debian12"
+	// dockerRuntimeImageTag is the tag of dockerRuntimeImageName.
+	dockerRuntimeImageTag = "nonroot"
+	// dockerRuntimeImage is the container image with tag of Dockerfile runtime stage.
+	dockerRuntimeImage = dockerRuntimeImageName + ":" + dockerRuntimeImageTag
+)
+
+const (
+	// devcontainerImageName is the base image of devcontainer.json.
+	devcontainerImageName = "mcr.microsoft.com/devcontainers/base"
+	// devcontainerImageTag is the tag of devcontainerImageName.
+	devcontainerImageTag = "bookworm"
+	// devcontainerGoFeatureName is the devcontainer feature that installs go, gopls and golangci-lint.
+	devcontainerGoFeatureName = "ghcr.io/devcontainers/features/go"
+	// devcontainerGoFeatureVersion is the major version of devcontainerGoFeatureName.
+	devcontainerGoFeatureVersion = "1"
+)
+
+// cliDependencies is go modules that cli project template depends on.
+// mkgoprj pins these versions so that every generated project has the same
+// dependencies, and go.mod and go.sum can be generated without network.
+var cliDependencies = []Dependency{
+	{Path: "github.com/fatih/color", Version: "v1.18.0", RequiredBy: "internal/print/print.go"},
+	{Path: "github.com/google/go-cmp", Version: "v0.7.0", RequiredBy: "internal/print/print_test.go"},
+	{Path: "github.com/mattn/go-colorable", Version: "v0.1.14", RequiredBy: "internal/print/print.go"},
+	{Path: "github.com/spf13/cobra", Version: "v1.9.1", RequiredBy: "cmd/*.go"},
+	{Path: "github.com/inconshreveable/mousetrap", Version: "v1.1.0", Indirect: true, RequiredBy: "github.com/spf13/cobra"},
+	{Path: "github.com/mattn/go-isatty", Version: "v0.0.20", Indirect: true, RequiredBy: "github.com/fatih/color"},
+	{Path: "github.com/spf13/pflag", Version: "v1.0.6", Indirect: true, RequiredBy: "github.com/spf13/cobra"},
+	{Path: "golang.org/x/sys", Version: "v0.29.0", Indirect: true, RequiredBy: "github.com/fatih/color"},
+}
+
+// cliGoSum is go.sum of cli project template. It must be updated with cliDependencies.
+const cliGoSum = `github.com/cpuguy83/go-md2man/v2 v2.0.6/go.mod h1:oOW0eioCTA6cOiMLiUPZOpcVxMig6NIQQ7OS05n1F4g=
+github.com/fatih/color v1.18.0 h1:S8gINlzdQ840/4pfAwic/ZE0djQEH3wM94VfqLTZcOM=
+github.com/fatih/color v1.18.0/go.mod h1:4FelSpRwEGDpQ12mAdzqdOukCy4u8WUtOY6lkT/6HfU=
+github.com/google/go-cmp v0.7.0 h1:wk8382ETsv4JYUZwIsn6YpYiWiBsYLSJiTsyBybVuN8=
+github.com/google/go-cmp v0.7.0/go.mod h1:pXiqmnSA92OHEEa9HXL2W4E7lf9JzCmGVUdgjX3N/iU=
+github.com/inconshreveable/mousetrap v1.1.0 h1:wN+x4NVGpMsO7ErUn/mUI3vEoE6Jt13X2s0bqwp9tc8=
+github.com/inconshreveable/mousetrap v1.1.0/go.mod h1:vpF70FUmC8bwa3OWnCshd2FqLfsEA9PFc4w1p2J65bw=
+github.com/mattn/go-colorable v0.1.14 h1:9A9LHSqF/7dyVVX6g0U9cwm9pG3kP9gSzcuIPHPsaIE=
+github.com/mattn/go-colorable v0.1.14/go.mod h1:6LmQG8QLFO4G5z1gPvYEzlUgJ2wF+stgPZH1UqBm1s8=
+github.com/mattn/go-isatty v0.0.20 h1:xfD0iDuEKnDkl03q4limB+vH+GxLEtL/jb4xVJSWWEY=
+github.com/mattn/go-isatty v0.0.20/go.mod h1:W+V8PltTTMOvKvAeJH7IuucS94S2C6jfK/D7dTCTo3Y=
+github.com/russross/blackfriday/v2 v2.1.0/go.mod h1:+Rmxgy9KzJVeS9/2gXHxylqXiyQDYRxCVz55jmeOWTM=
+github.com/spf13/cobra v1.9.1 h1:CXSaggrXdbHK9CF+8ywj8Amf7PBRmPCOJugH954Nnlo=
+github.com/spf13/cobra v1.9.1/go.mod h1:nDyEzZ8ogv936Cinf6g1RU9MRY64Ir93oCnqb9wxYW0=
+github.com/spf13/pflag v1.0.6 h1:jFzHGLGAlb3ruxLB8MhbI6A8+AQX/2eW4qeyNZXNp2o=
+github.com/spf13/pflag v1.0.6/go.mod h1:McXfInJRrz4CZXVZOBLb0bTZqETkiAhM9Iw0y3An2Bg=
+golang.org/x/sys v0.6.0/go.mod h1:oPkhp1MJrh7nUepCBck5+mAzfO9JrbApNNgaTdGDITg=
+golang.org/x/sys v0.29.0 h1:TPYlXGxvx1MGTn2GiZDhnjPA9wZzZeGKHHmKhHYvgaU=
+golang.org/x/sys v0.29.0/go.mod h1:/VUhepiaJMQUp4+oa/7Zr1D23ma6VTLIYjOOTFZPUcA=
+gopkg.in/check.v1 v0.0.0-20161208181325-20d25e280405/go.mod h1:Co6ibVJAznAaIkqp8huTwlJQCZ016jof/cbN4VW5Yz0=
+gopkg.in/yaml.v3 v3.0.1/go.mod h1:K4uyk7z7BCEPqu6E+C64Yfv1cQ7kz7rIZviUmN+EgEM=
+`
+
+// Dependencies returns go modules that the project template depends on.
+func Dependencies(lib, cli bool) []Dependency {
+	if !lib && cli {
+		return cliDependencies
+	}
+	return []Dependency{}
+}
+
+// DirectDependencies returns go modules that the generated source code imports.
+func DirectDependencies(lib, cli bool) []Dependency {
+	deps := []Dependency{}
+	for _, v := range Dependencies(lib, cli) {
+		if !v.Indirect {
+			deps = append(deps, v)
+		}
+	}
+	return deps
+}
+
+// Tools returns the external tools that the generated files use.
+// The version of the tools that mkgoprj does not pin (e.g. pre-commit) is "-".
+func Tools(lib bool, opt Option) []Tool {
+	preset := opt.LintPreset
+	if preset == "" {
+		preset = LintPresetStandard
+	}
+	tools := []Tool{
+		{Name: "golangci-lint", Version: golangciLintVersion, RequiredBy: ".github/workflows/reviewdog.yml"},
+		{Name: "golangci-lint", Version: golangciLintVersion, RequiredBy: ".golangci.yml (" + preset + " preset)"},
+	}
+	if !lib && opt.Docker {
+		tools = append(tools,
+			Tool{Name: "golang", Version: opt.GoVersion, RequiredBy: "Dockerfile"},
+			Tool{Name: dockerRuntimeImageName, Version: dockerRuntimeImageTag, RequiredBy: "Dockerfile"})
+	}
+	switch opt.Hooks {
+	case HooksGit:
+		tools = append(tools,
+			Tool{Name: "golangci-lint", Version: golangciLintVersion, RequiredBy: ".githooks/pre-commit"})
+	case HooksPreCommit:
+		tools = append(tools,
+			Tool{Name: "pre-commit", Version: "-", RequiredBy: ".pre-commit-config.yaml"},
+			Tool{Name: "golangci-lint", Version: golangciLintVersion, RequiredBy: ".pre-commit-config.yaml"})
+	}
+	if opt.Devcontainer {
+		tools = append(tools,
+			Tool{Name: devcontainerImageName, Version: devcontainerImageTag, RequiredBy: ".devcontainer/devcontainer.json"},
+			Tool{Name: devcontainerGoFeatureName, Version: devcontainerGoFeatureVersion, RequiredBy: ".devcontainer/devcontainer.json"},
+			Tool{Name: "golang", Version: opt.GoVersion, RequiredBy: ".devcontainer/devcontainer.json"},
+			Tool{Name: "golangci-lint", Version: golangciLintVersion, RequiredBy: ".devcontainer/devcontainer.json"})
+	}
+	return tools
+}
@@ -2,17 +2,124 @@
 package target
 
 import (
+	"go/token"
+	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
+	"github.com/nao1215/mkgoprj/v2/internal/changelog"
 	"github.com/nao1215/mkgoprj/v2/internal/gotool"
 )
 
+// Option is the optional setting that changes the generated templates.
+type Option struct {
+	Docker       bool   // whether to generate Dockerfile and container build assets
+	LintPreset   string // golangci-lint preset (LintPresetMinimal, LintPresetStandard or LintPresetStrict)
+	GoVersion    string // go version used by go.mod, workflows and Dockerfile (e.g. "1.22")
+	Offline      bool   // whether to generate go.mod and go.sum instead of "$ go mod init/tidy"
+	Package      string // package name of library project. Empty means PackageName(project name)
+	BinName      string // binary name of cli project. Empty means BinaryName(project name)
+	Hooks        string // local git hooks (HooksNone, HooksGit or HooksPreCommit). Empty means HooksNone
+	Devcontainer bool   // whether to generate .devcontainer, .vscode settings and .editorconfig
+}
+
+const (
+	// LintPresetMinimal enables only the linters that find real bugs.
+	LintPresetMinimal = "minimal"
+	// LintPresetStandard adds style and error handling linters to the minimal preset.
+	LintPresetStandard = "standard"
+	// LintPresetStrict adds complexity and code smell linters to the standard preset.
+	LintPresetStrict = "strict"
+)
+
+// LintPresets returns all golangci-lint preset names.
+func LintPresets() []string {
+	return []string{LintPresetMinimal, LintPresetStandard, LintPresetStrict}
+}
+
+// IsLintPreset reports whether preset is a valid golangci-lint preset name.
+func IsLintPreset(preset string) bool {
+	for _, v := range LintPresets() {
+		if v == preset {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// HooksNone does not generate git hooks.
+	HooksNone = "none"
+	// HooksGit generates .githooks directory that is enabled by "$ make hooks" (core.hooksPath).
+	HooksGit = "githooks"
+	// HooksPreCommit generates .pre-commit-config.yaml for pre-commit framework.
+	HooksPreCommit = "pre-commit"
+)
+
+// HookStyles returns all git hooks style names.
+func HookStyles() []string {
+	return []string{HooksNone, HooksGit, HooksPreCommit}
+}
+
+// IsHookStyle reports whether style is a valid git hooks style name.
+func IsHookStyle(style string) bool {
+	for _, v := range HookStyles() {
+		if v == style {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageName returns the go package name derived from the project name.
+// "go-" prefix is removed and characters that can not be used in go identifier are removed
+// (e.g. "go-my-lib" -> "mylib"), and "pkg" is added if the result is empty, starts with
+// a digit or is a go keyword.
+func PackageName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(trimGoPrefix(name)) {
+		if r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+		}
+	}
+	pkg := sb.String()
+	if pkg == "" || unicode.IsDigit(rune(pkg[0])) {
+		pkg = "pkg" + pkg
+	}
+	if token.IsKeyword(pkg) {
+		pkg += "pkg"
+	}
+	return pkg
+}
+
+// BinaryName returns the binary name derived from the project name.
+// "go-" prefix is removed (e.g. "go-widget" -> "widget").
+func BinaryName(name string) string {
+	return trimGoPrefix(name)
+}
+
+// trimGoPrefix removes "go-" prefix that is often used in repository name.
+func trimGoPrefix(name string) string {
+	if trimmed := strings.TrimPrefix(name, "go-"); trimmed != "" {
+		return trimmed
+	}
+	return name
+}
+
+const (
+	// fileMode is the permission of the generated files.
+	fileMode os.FileMode = 0644
+	// executableMode is the permission of the generated scripts (e.g. git hooks).
+	executableMode os.FileMode = 0755
+)
+
 // Dirs returns the directory to be created.
 // name   : Project name
 // lib    : Whether to create library project
 // noRoot : Whether to create the project root directory (project name directory)
-func Dirs(name string, lib, cli, noRoot bool) []string {
+// opt    : Optional setting that adds directories (e.g. .githooks, .devcontainer)
+func Dirs(name string, lib, cli, noRoot bool, opt Option) []string {
 	dirs := []string{}
 	if noRoot {
 		dirs = append(dirs, filepath.Join(".github", "workflows"))
@@ -35,15 +142,44 @@ func Dirs(name string, lib, cli, noRoot bool) []string {
 			dirs = append(dirs, filepath.Join(name, "internal", "print"))
 		}
 	}
+
+	if opt.Hooks == HooksGit {
+		if noRoot {
+			dirs = append(dirs, ".githooks")
+		} else {
+			dirs = append(dirs, filepath.Join(name, ".githooks"))
+		}
+	}
+
+	if opt.Devcontainer {
+		if noRoot {
+			dirs = append(dirs, ".devcontainer")
+			dirs = append(dirs, ".vscode")
+		} else {
+			dirs = append(dirs, filepath.Join(name, ".devcontainer"))
+			dirs = append(dirs, filepath.Join(name, ".vscode"))
+		}
+	}
 	return dirs
 }
 
-// Files returns the directory to be created.
-func Files(name, importPath string, lib, cli, noRoot bool) map[string]string {
+// Files returns the files to be created (key=file path, value=text in file) and
+// their permissions (key=file path). The template that generates a script declares
+// executableMode with its path, and the other files are fileMode.
+func Files(name, importPath string, lib, cli, noRoot bool, opt Option) (map[string]string, map[string]os.FileMode) {
 	files := map[string]string{}
+	modes := map[string]os.FileMode{}
+	pkg := opt.Package
+	if pkg == "" {
+		pkg = PackageName(name)
+	}
+	bin := opt.BinName
+	if bin == "" {
+		bin = BinaryName(name)
+	}
 
 	if lib {
-		path, code := librarySourceCodeFile(name, noRoot)
+		path, code := librarySourceCodeFile(name, pkg, noRoot)
 		files[path] = code
 	} else if cli {
 		path, code := cliMainSourceCodeFile(name, importPath, noRoot)
@@ -51,46 +187,92 @@ func Files(name, importPath string, lib, cli, noRoot bool) map[string]string {
 	}
 
 	if !cli {
-		path, code := mainTestFile(name, lib, noRoot)
+		path, code := mainTestFile(name, pkg, lib, noRoot)
 		files[path] = code
 	}
 
 	if cli {
-		path, code := rootFile(name, importPath, noRoot)
+		path, code := rootFile(name, bin, importPath, noRoot)
 		files[path] = code
-		path, code = versionFile(name, noRoot)
+		path, code = versionFile(name, bin, noRoot)
+		files[path] = code
+		path, code = completionFile(name, importPath, noRoot)
+		files[path] = code
+		path, code = completionTestFile(name, noRoot)
 		files[path] = code
 		path, code = printFile(name, importPath, noRoot)
 		files[path] = code
-		path, code = printTestFile(name, noRoot)
+		path, code = printTestFile(name, bin, noRoot)
+		files[path] = code
+		path, code = rootTestFile(name, noRoot)
+		files[path] = code
+		path, code = versionTestFile(name, noRoot)
 		files[path] = code
 	}
 
-	path, code := makefile(name, importPath, lib, cli, noRoot)
+	path, code := makefile(name, bin, importPath, lib, cli, noRoot, opt.Docker, opt.Hooks)
 	files[path] = code
 
+	switch opt.Hooks {
+	case HooksGit:
+		var mode os.FileMode
+		path, code, mode = preCommitHook(name, noRoot)
+		files[path], modes[path] = code, mode
+		path, code, mode = commitMsgHook(name, noRoot)
+		files[path], modes[path] = code, mode
+	case HooksPreCommit:
+		path, code = preCommitConfig(name, noRoot)
+		files[path] = code
+	}
+
 	path, code = changelogFile(name, noRoot)
 	files[path] = code
 
+	path, code = gitignore(name, bin, lib, noRoot)
+	files[path] = code
+
+	if opt.Devcontainer {
+		path, code = devcontainer(name, noRoot, opt.GoVersion)
+		files[path] = code
+		path, code = vscodeSettings(name, noRoot)
+		files[path] = code
+		path, code = vscodeLaunch(name, bin, cli, noRoot)
+		files[path] = code
+		path, code = editorconfig(name, noRoot)
+		files[path] = code
+	}
+
 	if !lib {
-		path, code = githubBuildYml(name, noRoot)
+		path, code = githubBuildYml(name, noRoot, opt.GoVersion)
+		files[path] = code
+		path, code = githubRelease(name, noRoot, opt.GoVersion)
+		files[path] = code
+		path, code = goreleaser(name, bin, importPath, noRoot, cli)
+		files[path] = code
+	}
+
+	if !lib && opt.Docker {
+		path, code = dockerfile(name, bin, noRoot, opt.GoVersion)
 		files[path] = code
-		path, code = githubRelease(name, noRoot)
+		path, code = dockerignore(name, bin, noRoot)
 		files[path] = code
-		path, code = goreleaser(name, importPath, noRoot, cli)
+		path, code = githubDocker(name, noRoot)
 		files[path] = code
 	}
 
 	// contributor command has many bugs. Not use it.
-	//path, code = githubContributors(name, noRoot)
+	//path, code = githubContributors(name, noRoot, opt.GoVersion)
 	//files[path] = code
 
-	path, code = githubPlatformTest(name, noRoot)
+	path, code = githubPlatformTest(name, noRoot, opt.GoVersion)
 	files[path] = code
 
 	path, code = githubReviewDog(name, noRoot)
 	files[path] = code
 
+	path, code = golangciLint(name, noRoot, opt.LintPreset)
+	files[path] = code
+
 	path, code = codeOfConduct(name, noRoot)
 	files[path] = code
 
@@ -103,7 +285,21 @@ func Files(name, importPath string, lib, cli, noRoot bool) map[string]string {
 	path, code = issueTemplate(name, noRoot)
 	files[path] = code
 
-	return files
+	if opt.Offline {
+		path, code = goModFile(name, importPath, noRoot, opt.GoVersion, Dependencies(lib, cli))
+		files[path] = code
+		if !lib && cli {
+			path, code = goSumFile(name, noRoot, cliGoSum)
+			files[path] = code
+		}
+	}
+
+	for path := range files {
+		if _, ok := modes[path]; !ok {
+			modes[path] = fileMode
+		}
+	}
+	return files, modes
 }
 
 func cliMainSourceCodeFile(name, importPath string, noRoot bool) (string, string) {
@@ -125,12 +321,12 @@ func main() {
 	return path, strings.ReplaceAll(code, "XXX_IMPORT_PATH_XXX", filepath.Join(importPath, "cmd"))
 }
 
-func librarySourceCodeFile(name string, noRoot bool) (string, string) {
+func librarySourceCodeFile(name, pkg string, noRoot bool) (string, string) {
 	var path string
 	if noRoot {
-		path = filepath.Join(name + ".go")
+		path = filepath.Join(pkg + ".go")
 	} else {
-		path = filepath.Join(name, name+".go")
+		path = filepath.Join(name, pkg+".go")
 	}
 
 	code := `package XXX_PKG_XXX
@@ -139,10 +335,10 @@ func HelloWorld() string {
 	return "Hello, World"
 }
 `
-	return path, strings.ReplaceAll(code, "XXX_PKG_XXX", name)
+	return path, strings.ReplaceAll(code, "XXX_PKG_XXX", pkg)
 }
 
-func mainTestFile(name string, libProject, noRoot bool) (string, string) {
+func mainTestFile(name, pkg string, libProject, noRoot bool) (string, string) {
 	code := `package XXX_PKG_XXX
 
 import "testing"
@@ -156,16 +352,58 @@ func TestHelloWorld(t *testing.T) {
 	var path string
 	if libProject {
 		if noRoot {
-			path = filepath.Join(name + "_test.go")
+			path = filepath.Join(pkg + "_test.go")
 		} else {
-			path = filepath.Join(name, name+"_test.go")
+			path = filepath.Join(name, pkg+"_test.go")
 		}
-		code = strings.ReplaceAll(code, "XXX_PKG_XXX", name)
+		code = strings.ReplaceAll(code, "XXX_PKG_XXX", pkg)
 	}
 	return path, code
 }
 
-func makefile(name, importPath string, libProject, cli, noRoot bool) (string, string) {
+// goModFile returns go.mod with pinned dependencies. It is same as the result of
+// "$ go mod init", "$ go mod edit -go -toolchain" and "$ go mod tidy".
+func goModFile(name, importPath string, noRoot bool, goVersion string, deps []Dependency) (string, string) {
+	var path string
+	if noRoot {
+		path = "go.mod"
+	} else {
+		path = filepath.Join(name, "go.mod")
+	}
+
+	data := "module " + importPath + "\n\ngo " + goVersion + "\n"
+	if toolchain := gotool.Toolchain(goVersion); toolchain != "" {
+		data += "\ntoolchain " + toolchain + "\n"
+	}
+
+	direct, indirect := "", ""
+	for _, v := range deps {
+		if v.Indirect {
+			indirect += "\t" + v.Path + " " + v.Version + " // indirect\n"
+		} else {
+			direct += "\t" + v.Path + " " + v.Version + "\n"
+		}
+	}
+	if direct != "" {
+		data += "\nrequire (\n" + direct + ")\n"
+	}
+	if indirect != "" {
+		data += "\nrequire (\n" + indirect + ")\n"
+	}
+	return path, data
+}
+
+func goSumFile(name string, noRoot bool, sum string) (string, string) {
+	var path string
+	if noRoot {
+		path = "go.sum"
+	} else {
+		path = filepath.Join(name, "go.sum")
+	}
+	return path, sum
+}
+
+func makefile(name, bin, importPath string, libProject, cli, noRoot, docker bool, hooks string) (string, string) {
 	var path string
 	if noRoot {
 		path = "Makefile"
@@ -173,7 +411,7 @@ func makefile(name, importPath string, libProject, cli, noRoot bool) (string, st
 		path = filepath.Join(name, "Makefile")
 	}
 
-	code := `.PHONY: build test clean vet fmt chkfmt
+	code := `.PHONY: build test clean vet fmt chkfmt lint changelog
 
 APP         = XXX_APP_XXX
 VERSION     = $(shell git describe --tags --abbrev=0)
@@ -191,10 +429,12 @@ GOARCH      = ""
 GO_PKGROOT  = ./...
 GO_PACKAGES = $(shell $(GO_LIST) $(GO_PKGROOT))
 GO_LDFLAGS  = -ldflags '-X XXX_IMPORT_PATH_XXX/cmd.Version=${VERSION}'
+GOLANGCI_LINT = golangci-lint
+MKGOPRJ     = mkgoprj
 
 XXX_ONLY_APP_XXX
-
-clean: ## Clean project
+XXX_DOCKER_XXX
+XXX_HOOKS_XXXclean: ## Clean project
 	-rm -rf $(APP) cover.out cover.html
 
 test: ## Start test
@@ -207,6 +447,12 @@ vet: ## Start go vet
 fmt: ## Format go source code 
 	$(GO_FORMAT) $(GO_PKGROOT)
 
+lint: ## Start golangci-lint (same config as reviewdog)
+	$(GOLANGCI_LINT) run --config .golangci.yml $(GO_PKGROOT)
+
+changelog: ## Update Changelog.md from git history (Conventional Commits)
+	$(MKGOPRJ) changelog
+
 .DEFAULT_GOAL := help
 help:  
 	@grep -E '^[0-9a-zA-Z_-]+[[:blank:]]*:.*?## .*$$' $(MAKEFILE_LIST) | sort \
@@ -216,6 +462,29 @@ help:
 	strOnlyApp := `build:  ## Build binary
 	env GO111MODULE=on GOOS=$(GOOS) GOARCH=$(GOARCH) $(GO_BUILD) $(GO_LDFLAGS) -o $(APP) XXX_CODE_XXX`
 
+	strDocker := `
+.PHONY: docker-build docker-run
+DOCKER_IMAGE = $(APP):latest
+
+docker-build: ## Build container image
+	docker build --build-arg VERSION=$(VERSION) -t $(DOCKER_IMAGE) .
+
+docker-run: ## Run container image
+	docker run --rm $(DOCKER_IMAGE)
+`
+
+	strGitHooks := `.PHONY: hooks
+hooks: ## Use .githooks as git hooks (gofmt, go vet, lint and Conventional Commits)
+	git config core.hooksPath .githooks
+
+`
+
+	strPreCommit := `.PHONY: hooks
+hooks: ## Install pre-commit hooks (gofmt, go vet, lint and Conventional Commits)
+	pre-commit install --hook-type pre-commit --hook-type commit-msg
+
+`
+
 	if libProject {
 		code = strings.Replace(code, "XXX_ONLY_APP_XXX", "", 1)
 	} else if cli {
@@ -225,28 +494,322 @@ help:
 		code = strings.Replace(code, "XXX_ONLY_APP_XXX", strOnlyApp, 1)
 		code = strings.Replace(code, "XXX_CODE_XXX", filepath.Join("cmd", name, "main.go"), 1)
 	}
-	code = strings.Replace(code, "XXX_APP_XXX", name, 1)
+	if docker && !libProject {
+		code = strings.Replace(code, "XXX_DOCKER_XXX", strDocker, 1)
+	} else {
+		code = strings.Replace(code, "XXX_DOCKER_XXX", "", 1)
+	}
+	switch hooks {
+	case HooksGit:
+		code = strings.Replace(code, "XXX_HOOKS_XXX", strGitHooks, 1)
+	case HooksPreCommit:
+		code = strings.Replace(code, "XXX_HOOKS_XXX", strPreCommit, 1)
+	default:
+		code = strings.Replace(code, "XXX_HOOKS_XXX", "", 1)
+	}
+	code = strings.Replace(code, "XXX_APP_XXX", bin, 1)
 	code = strings.Replace(code, "XXX_IMPORT_PATH_XXX", importPath, 1)
 	return path, code
 }
 
-func changelogFile(name string, noRoot bool) (string, string) {
+// conventionalCommitTypes is the commit types accepted by the commit-msg hooks.
+const conventionalCommitTypes = "build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test"
+
+func preCommitHook(name string, noRoot bool) (string, string, os.FileMode) {
 	var path string
 	if noRoot {
-		path = "Changelog.md"
+		path = filepath.Join(".githooks", "pre-commit")
 	} else {
-		path = filepath.Join(name, "Changelog.md")
+		path = filepath.Join(name, ".githooks", "pre-commit")
+	}
+
+	data := `#!/bin/sh
+# pre-commit hook runs the same checks as the reviewdog workflow.
+# Enable it with "$ make hooks".
+set -eu
+
+files=$(git diff --cached --name-only --diff-filter=ACM -- '*.go')
+if [ -n "$files" ]; then
+	unformatted=$(gofmt -l $files)
+	if [ -n "$unformatted" ]; then
+		echo "pre-commit: the following files are not formatted. Run 'make fmt'." >&2
+		echo "$unformatted" >&2
+		exit 1
+	fi
+fi
+
+go vet ./...
+make lint
+`
+	return path, data, executableMode
+}
+
+func commitMsgHook(name string, noRoot bool) (string, string, os.FileMode) {
+	var path string
+	if noRoot {
+		path = filepath.Join(".githooks", "commit-msg")
+	} else {
+		path = filepath.Join(name, ".githooks", "commit-msg")
+	}
+
+	data := `#!/bin/sh
+# commit-msg hook enforces Conventional Commits (https://www.conventionalcommits.org/).
+# e.g. "feat: add --output option", "fix(cmd)!: change exit status"
+# Enable it with "$ make hooks".
+set -eu
+
+msg=$(head -n 1 "$1")
+case "$msg" in
+"Merge "* | "Revert "* | "fixup! "* | "squash! "*)
+	exit 0
+	;;
+esac
+
+if ! printf '%s\n' "$msg" | grep -Eq '^(XXX_TYPES_XXX)(\([a-zA-Z0-9_./-]+\))?!?: .+'; then
+	echo "commit-msg: the commit message does not follow Conventional Commits." >&2
+	echo "  expected: <type>[(scope)][!]: <description>" >&2
+	echo "  type    : XXX_TYPES_XXX" >&2
+	echo "  got     : $msg" >&2
+	exit 1
+fi
+`
+	data = strings.ReplaceAll(data, "XXX_TYPES_XXX", conventionalCommitTypes)
+	return path, data, executableMode
+}
+
+func preCommitConfig(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = ".pre-commit-config.yaml"
+	} else {
+		path = filepath.Join(name, ".pre-commit-config.yaml")
+	}
+
+	data := `# pre-commit (https://pre-commit.com/) runs the same checks as the reviewdog workflow.
+# Install the hooks with "$ make hooks".
+default_install_hook_types: [pre-commit, commit-msg]
+repos:
+  - repo: local
+    hooks:
+      - id: gofmt
+        name: gofmt
+        entry: sh -c 'out=$(gofmt -l "$@"); test -z "$out" || { echo "$out"; exit 1; }' --
+        language: system
+        types: [go]
+      - id: go-vet
+        name: go vet
+        entry: go vet ./...
+        language: system
+        types: [go]
+        pass_filenames: false
+      - id: golangci-lint
+        name: golangci-lint (same config as reviewdog)
+        entry: make lint
+        language: system
+        types: [go]
+        pass_filenames: false
+      - id: conventional-commits
+        name: Conventional Commits
+        entry: '\A((XXX_TYPES_XXX)(\([a-zA-Z0-9_./-]+\))?!?: .+|Merge .+|Revert .+|fixup! .+|squash! .+)'
+        language: pygrep
+        args: [--multiline, --negate]
+        stages: [commit-msg]
+`
+	data = strings.ReplaceAll(data, "XXX_TYPES_XXX", conventionalCommitTypes)
+	return path, data
+}
+
+func devcontainer(name string, noRoot bool, goVersion string) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join(".devcontainer", "devcontainer.json")
+	} else {
+		path = filepath.Join(name, ".devcontainer", "devcontainer.json")
+	}
+
+	data := `{
+  "name": "XXX_NAME_XXX",
+  "image": "XXX_IMAGE_XXX",
+  "features": {
+    // go feature installs go, gopls and golangci-lint.
+    "XXX_GO_FEATURE_XXX": {
+      "version": "XXX_VER_XXX",
+      "golangciLintVersion": "XXX_LINT_VER_XXX"
+    }
+  },
+  "postCreateCommand": "go mod download",
+  "customizations": {
+    "vscode": {
+      "extensions": [
+        "golang.go",
+        "EditorConfig.EditorConfig"
+      ]
+    }
+  }
+}
+`
+	data = strings.Replace(data, "XXX_NAME_XXX", name, 1)
+	data = strings.Replace(data, "XXX_IMAGE_XXX", devcontainerImageName+":"+devcontainerImageTag, 1)
+	data = strings.Replace(data, "XXX_GO_FEATURE_XXX", devcontainerGoFeatureName+":"+devcontainerGoFeatureVersion, 1)
+	data = strings.Replace(data, "XXX_VER_XXX", goVersion, 1)
+	// go feature takes the version without "v" prefix (e.g. "2.1.6").
+	data = strings.Replace(data, "XXX_LINT_VER_XXX", strings.TrimPrefix(golangciLintVersion, "v"), 1)
+	return path, data
+}
+
+func vscodeSettings(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join(".vscode", "settings.json")
+	} else {
+		path = filepath.Join(name, ".vscode", "settings.json")
+	}
+
+	return path, `{
+  "go.useLanguageServer": true,
+  "go.lintTool": "golangci-lint",
+  "go.lintFlags": [
+    "--config=${workspaceFolder}/.golangci.yml"
+  ],
+  "go.testFlags": [
+    "-v"
+  ],
+  "[go]": {
+    "editor.formatOnSave": true,
+    "editor.codeActionsOnSave": {
+      "source.organizeImports": "explicit"
+    }
+  },
+  "files.eol": "\n"
+}
+`
+}
+
+// vscodeLaunch returns launch.json that debugs main package of cli project,
+// or the tests of the other projects.
+func vscodeLaunch(name, bin string, cli, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join(".vscode", "launch.json")
+	} else {
+		path = filepath.Join(name, ".vscode", "launch.json")
+	}
+
+	if cli {
+		data := `{
+  "version": "0.2.0",
+  "configurations": [
+    {
+      "name": "Debug XXX_NAME_XXX",
+      "type": "go",
+      "request": "launch",
+      "mode": "auto",
+      "program": "${workspaceFolder}",
+      "args": []
+    },
+    {
+      "name": "Debug test in current package",
+      "type": "go",
+      "request": "launch",
+      "mode": "test",
+      "program": "${fileDirname}"
+    }
+  ]
+}
+`
+		return path, strings.Replace(data, "XXX_NAME_XXX", bin, 1)
+	}
+	return path, `{
+  "version": "0.2.0",
+  "configurations": [
+    {
+      "name": "Debug test",
+      "type": "go",
+      "request": "launch",
+      "mode": "test",
+      "program": "${workspaceFolder}"
+    },
+    {
+      "name": "Debug test in current package",
+      "type": "go",
+      "request": "launch",
+      "mode": "test",
+      "program": "${fileDirname}"
+    }
+  ]
+}
+`
+}
+
+func editorconfig(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = ".editorconfig"
+	} else {
+		path = filepath.Join(name, ".editorconfig")
 	}
 
-	data := `# Changelog
-All notable changes to this project will be documented in this file.  
-The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/).   
-This project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+	return path, `root = true
+
+[*]
+charset = utf-8
+end_of_line = lf
+insert_final_newline = true
+trim_trailing_whitespace = true
+
+[*.go]
+indent_style = tab
+indent_size = 4
+
+[Makefile]
+indent_style = tab
+
+[*.{json,yml,yaml}]
+indent_style = space
+indent_size = 2
+
+# Markdown uses trailing spaces as line break.
+[*.md]
+trim_trailing_whitespace = false
+`
+}
+
+// gitignore returns .gitignore that ignores the artifacts of Makefile and goreleaser,
+// so that "$ make test" does not make the worktree dirty (e.g. before "$ mkgoprj release").
+func gitignore(name, bin string, lib, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = ".gitignore"
+	} else {
+		path = filepath.Join(name, ".gitignore")
+	}
+
+	data := `# Artifacts of "$ make test" (removed by "$ make clean")
+cover.out
+cover.html
+`
+	if !lib {
+		data += `
+# Artifacts of "$ make build" and goreleaser
+/` + bin + `
+/dist/
 `
+	}
 	return path, data
 }
 
-func githubBuildYml(name string, noRoot bool) (string, string) {
+func changelogFile(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = "Changelog.md"
+	} else {
+		path = filepath.Join(name, "Changelog.md")
+	}
+	// "$ make changelog" fills the sections from git history.
+	return path, changelog.DefaultHeader
+}
+
+func githubBuildYml(name string, noRoot bool, goVersion string) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join(".github", "workflows", "build.yml")
@@ -276,11 +839,11 @@ jobs:
     - name: Build
       run: make build
 `
-	data = strings.Replace(data, "XXX_VER_XXX", gotool.Version(), 1)
+	data = strings.Replace(data, "XXX_VER_XXX", goVersion, 1)
 	return path, data
 }
 
-func githubPlatformTest(name string, noRoot bool) (string, string) {
+func githubPlatformTest(name string, noRoot bool, goVersion string) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join(".github", "workflows", "platform_test.yml")
@@ -311,14 +874,14 @@ jobs:
 
       - uses: actions/setup-go@v3
         with:
-          go-version: "1"
-          check-latest: true
+          go-version: "XXX_VER_XXX"
 
       - name: Run unit test
         run: |
           go mod download
           go test -race -v ./...
 `
+	data = strings.Replace(data, "XXX_VER_XXX", goVersion, 1)
 	return path, data
 }
 
@@ -344,6 +907,8 @@ jobs:
       - name: golangci-lint
         uses: reviewdog/action-golangci-lint@v2
         with:
+          golangci_lint_version: XXX_LINT_VER_XXX
+          golangci_lint_flags: "--config=.golangci.yml ./..."
           reporter: github-pr-review
           level: warning
 
@@ -371,10 +936,92 @@ jobs:
           reporter: github-pr-review
           level: warning
 `
+	data = strings.Replace(data, "XXX_LINT_VER_XXX", golangciLintVersion, 1)
 	return path, data
 }
 
-func githubContributors(name string, noRoot bool) (string, string) {
+// golangciLint returns .golangci.yml for the preset. The same file is used by
+// "$ make lint" and the reviewdog workflow.
+func golangciLint(name string, noRoot bool, preset string) (string, string) {
+	var path string
+	if noRoot {
+		path = ".golangci.yml"
+	} else {
+		path = filepath.Join(name, ".golangci.yml")
+	}
+
+	minimal := `    - errcheck
+    - govet
+    - ineffassign
+    - staticcheck
+    - unused
+`
+	standard := `    - bodyclose
+    - errorlint
+    - misspell
+    - nilerr
+    - revive
+    - unconvert
+    - unparam
+`
+	strict := `    - dupl
+    - gocognit
+    - gocritic
+    - gocyclo
+    - gosec
+    - nakedret
+    - prealloc
+`
+	standardSettings := `  settings:
+    misspell:
+      locale: US
+`
+	strictSettings := `  settings:
+    gocognit:
+      min-complexity: 20
+    gocyclo:
+      min-complexity: 15
+    misspell:
+      locale: US
+    nakedret:
+      max-func-lines: 10
+`
+
+	var linters, settings, formatters string
+	switch preset {
+	case LintPresetMinimal:
+		linters = minimal
+		formatters = "    - gofmt\n"
+	case LintPresetStrict:
+		linters = minimal + standard + strict
+		settings = strictSettings
+		formatters = "    - gofmt\n    - goimports\n"
+	default:
+		preset = LintPresetStandard
+		linters = minimal + standard
+		settings = standardSettings
+		formatters = "    - gofmt\n    - goimports\n"
+	}
+
+	data := `# golangci-lint configuration (preset: XXX_PRESET_XXX)
+# This file is used by "$ make lint" and the reviewdog workflow.
+version: "2"
+
+linters:
+  default: none
+  enable:
+XXX_LINTERS_XXXXXX_SETTINGS_XXX
+formatters:
+  enable:
+XXX_FORMATTERS_XXX`
+	data = strings.Replace(data, "XXX_PRESET_XXX", preset, 1)
+	data = strings.Replace(data, "XXX_LINTERS_XXX", linters, 1)
+	data = strings.Replace(data, "XXX_SETTINGS_XXX", settings, 1)
+	data = strings.Replace(data, "XXX_FORMATTERS_XXX", formatters, 1)
+	return path, data
+}
+
+func githubContributors(name string, noRoot bool, goVersion string) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join(".github", "workflows", "contributors.yml")
@@ -411,11 +1058,11 @@ jobs:
           git commit -m "Update Contributors List"
           git push origin HEAD:${GITHUB_REF}
 `
-	data = strings.Replace(data, "XXX_VER_XXX", gotool.Version(), 1)
+	data = strings.Replace(data, "XXX_VER_XXX", goVersion, 1)
 	return path, data
 }
 
-func githubRelease(name string, noRoot bool) (string, string) {
+func githubRelease(name string, noRoot bool, goVersion string) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join(".github", "workflows", "release.yml")
@@ -450,11 +1097,11 @@ jobs:
         env:
           GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}
 `
-	data = strings.Replace(data, "XXX_VER_XXX", gotool.Version(), 1)
+	data = strings.Replace(data, "XXX_VER_XXX", goVersion, 1)
 	return path, data
 }
 
-func goreleaser(name, importPath string, noRoot, cli bool) (string, string) {
+func goreleaser(name, bin, importPath string, noRoot, cli bool) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join(".goreleaser.yml")
@@ -500,13 +1147,94 @@ changelog:
       - "^docs:"
       - "^test:"	
 `
-	data = strings.Replace(data, "XXX_APP_NAME_XXX", name, 1)
+	data = strings.Replace(data, "XXX_APP_NAME_XXX", bin, 1)
 	data = strings.Replace(data, "XXX_BUILD_TARGET_XXX", ".", 1)
-	data = strings.Replace(data, "XXX_IMPORT_PATH_XXX", name, 1)
+	data = strings.Replace(data, "XXX_IMPORT_PATH_XXX", importPath, 1)
+	return path, data
+}
+
+func dockerfile(name, bin string, noRoot bool, goVersion string) (string, string) {
+	var path string
+	if noRoot {
+		path = "Dockerfile"
+	} else {
+		path = filepath.Join(name, "Dockerfile")
+	}
+	data := `# syntax=docker/dockerfile:1
+
+# Build stage: build the binary with the same flags as "$ make build".
+FROM golang:XXX_VER_XXX AS builder
+ARG VERSION=""
+ENV CGO_ENABLED=0
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN make build GOOS=linux VERSION=${VERSION}
+
+# Runtime stage: only the static binary on distroless, run as non-root user.
+FROM XXX_RUNTIME_IMAGE_XXX
+COPY --from=builder /src/XXX_APP_XXX /usr/local/bin/XXX_APP_XXX
+USER nonroot:nonroot
+ENTRYPOINT ["/usr/local/bin/XXX_APP_XXX"]
+`
+	data = strings.Replace(data, "XXX_VER_XXX", goVersion, 1)
+	data = strings.Replace(data, "XXX_RUNTIME_IMAGE_XXX", dockerRuntimeImage, 1)
+	data = strings.ReplaceAll(data, "XXX_APP_XXX", bin)
 	return path, data
 }
 
-func rootFile(name, importPath string, noRoot bool) (string, string) {
+func dockerignore(name, bin string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = ".dockerignore"
+	} else {
+		path = filepath.Join(name, ".dockerignore")
+	}
+	data := `.git
+.github
+.dockerignore
+Dockerfile
+dist
+cover.out
+cover.html
+XXX_APP_XXX
+`
+	data = strings.Replace(data, "XXX_APP_XXX", bin, 1)
+	return path, data
+}
+
+func githubDocker(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join(".github", "workflows", "docker.yml")
+	} else {
+		path = filepath.Join(name, ".github", "workflows", "docker.yml")
+	}
+	data := `name: Docker
+
+on:
+  push:
+    branches: [ main ]
+  pull_request:
+    branches: [ main ]
+
+jobs:
+  docker:
+
+    runs-on: ubuntu-latest
+    steps:
+    - uses: actions/checkout@v3
+      with:
+        fetch-depth: 0
+
+    - name: Build image
+      run: make docker-build
+`
+	return path, data
+}
+
+func rootFile(name, bin, importPath string, noRoot bool) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join("cmd", "root.go")
@@ -516,14 +1244,8 @@ func rootFile(name, importPath string, noRoot bool) (string, string) {
 	data := `package cmd
 
 import (
-	"bytes"
-	"fmt"
 	"os"
-	"path/filepath"
-	"runtime"
-	"strings"
 
-	"XXX_PATH_XXX/internal/print"
 	"github.com/spf13/cobra"
 )
 
@@ -535,276 +1257,538 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.SilenceErrors = true
-	deployShellCompletionFileIfNeeded(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
-
-func isWindows() bool {
-	return runtime.GOOS == "windows"
+`
+	data = strings.Replace(data, "XXX_NAME_XXX", bin, -1)
+	return path, data
 }
 
-// deployShellCompletionFileIfNeeded creates the shell completion file.
-// If the file with the same contents already exists, it is not created.
-func deployShellCompletionFileIfNeeded(cmd *cobra.Command) {
-	if !isWindows() {
-		makeBashCompletionFileIfNeeded(cmd)
-		makeFishCompletionFileIfNeeded(cmd)
-		makeZshCompletionFileIfNeeded(cmd)
+func completionFile(name, importPath string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join("cmd", "completion.go")
+	} else {
+		path = filepath.Join(name, "cmd", "completion.go")
 	}
-}
+	data := `package cmd
 
-func makeBashCompletionFileIfNeeded(cmd *cobra.Command) {
-	if existSameBashCompletionFile(cmd) {
-		return
-	}
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
-	path := bashCompletionFilePath()
-	bashCompletion := new(bytes.Buffer)
-	if err := cmd.GenBashCompletion(bashCompletion); err != nil {
-		print.Err(fmt.Errorf("can not generate bash completion content: %w", err))
-		return
-	}
+	"XXX_PATH_XXX/internal/print"
+	"github.com/spf13/cobra"
+)
 
-	if !isFile(path) {
-		fp, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0664)
+// shells is the shell names that support completion.
+var shells = []string{"bash", "fish", "zsh", "powershell"}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion",
+	Short: "Install, uninstall or print shell completion (" + strings.Join(shells, "/") + ")",
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:       "install SHELL",
+	Short:     "Install shell completion file and load it from shell rc file",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shells,
+	Run: func(cmd *cobra.Command, args []string) {
+		changes, err := installChanges(args[0], newCompletionPaths())
 		if err != nil {
-			print.Err(fmt.Errorf("can not open .bash_completion: %w", err))
+			print.Fatal(err)
 			return
 		}
-
-		if _, err := fp.WriteString(bashCompletion.String()); err != nil {
-			print.Err(fmt.Errorf("can not write .bash_completion %w", err))
+		if len(changes) == 0 {
+			print.Info(args[0] + " completion is already installed")
 			return
 		}
+		applyChangesWithConsent(cmd, changes)
+	},
+}
 
-		if err := fp.Close(); err != nil {
-			print.Err(fmt.Errorf("can not close .bash_completion %w", err))
+var completionUninstallCmd = &cobra.Command{
+	Use:       "uninstall SHELL",
+	Short:     "Remove shell completion file and the setting in shell rc file",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shells,
+	Run: func(cmd *cobra.Command, args []string) {
+		changes, err := uninstallChanges(args[0], newCompletionPaths())
+		if err != nil {
+			print.Fatal(err)
 			return
 		}
-		return
+		if len(changes) == 0 {
+			print.Info(args[0] + " completion is not installed")
+			return
+		}
+		applyChangesWithConsent(cmd, changes)
+	},
+}
+
+var completionPrintCmd = &cobra.Command{
+	Use:       "print SHELL",
+	Short:     "Print shell completion script to STDOUT",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shells,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := printCompletion(args[0], cmd.OutOrStdout()); err != nil {
+			print.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{completionInstallCmd, completionUninstallCmd} {
+		c.Flags().Bool("dry-run", false, "Only show the files to be changed")
+		c.Flags().BoolP("yes", "y", false, "Change files without confirmation")
+	}
+	completionCmd.AddCommand(completionInstallCmd)
+	completionCmd.AddCommand(completionUninstallCmd)
+	completionCmd.AddCommand(completionPrintCmd)
+	rootCmd.AddCommand(completionCmd)
+}
+
+// change is the file operation to install or uninstall the shell completion.
+type change struct {
+	path    string // file path to be changed
+	content []byte // new file content. It is not used when remove is true
+	remove  bool   // whether to remove the file
+}
+
+// String returns the description of the file operation.
+func (c change) String() string {
+	if c.remove {
+		return "remove " + c.path
+	}
+	if _, err := os.Stat(c.path); err == nil {
+		return "update " + c.path
 	}
+	return "create " + c.path
+}
 
-	fp, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0664)
+// applyChangesWithConsent shows the file operations and executes them after user consent.
+func applyChangesWithConsent(cmd *cobra.Command, changes []change) {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		print.Fatal(err)
+		return
+	}
+	yes, err := cmd.Flags().GetBool("yes")
 	if err != nil {
-		print.Err(fmt.Errorf("can not append .bash_completion: %w", err))
+		print.Fatal(err)
 		return
 	}
 
-	if _, err := fp.WriteString(bashCompletion.String()); err != nil {
-		print.Err(fmt.Errorf("can not write .bash_completion: %w", err))
+	for _, c := range changes {
+		print.Info(c.String())
+	}
+	if dryRun {
+		return
+	}
+	if !yes && !print.Question("Do you want to change the above files?") {
 		return
 	}
 
-	if err := fp.Close(); err != nil {
-		print.Err(fmt.Errorf("can not close .bash_completion: %w", err))
+	if err := applyChanges(changes); err != nil {
+		print.Fatal(err)
 		return
 	}
-	print.Info("append bash-completion: " + path)
+	print.Info("done. To activate the change, restart the shell")
 }
 
-func makeFishCompletionFileIfNeeded(cmd *cobra.Command) {
-	if isSameFishCompletionFile(cmd) {
-		return
+// printCompletion writes the completion script of shell to w.
+func printCompletion(shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletion(w)
+	case "fish":
+		return rootCmd.GenFishCompletion(w, false)
+	case "zsh":
+		return rootCmd.GenZshCompletion(w)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletion(w)
+	}
+	return fmt.Errorf("unsupported shell '%s' (choose from %s)", shell, strings.Join(shells, ", "))
+}
+
+// installChanges returns the file operations to install the completion of shell.
+// Files that already have the same content are not included.
+func installChanges(shell string, p completionPaths) ([]change, error) {
+	script := new(bytes.Buffer)
+	if err := printCompletion(shell, script); err != nil {
+		return nil, err
 	}
 
-	path := fishCompletionFilePath()
-	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
-		print.Err(fmt.Errorf("can not create fish-completion file: %w", err))
-		return
+	changes := []change{}
+	scriptPath := p.scriptFile(shell)
+	if current, err := os.ReadFile(scriptPath); err != nil || !bytes.Equal(current, script.Bytes()) {
+		changes = append(changes, change{path: scriptPath, content: script.Bytes()})
 	}
 
-	if err := cmd.GenFishCompletionFile(path, false); err != nil {
-		print.Err(fmt.Errorf("can not create fish-completion file: %w", err))
-		return
+	rc := p.rcFile(shell)
+	if rc == "" {
+		return changes, nil
+	}
+	current, err := readFileIfExists(rc)
+	if err != nil {
+		return nil, err
 	}
-	print.Info("create fish-completion file: " + path)
+	updated := upsertBlock(current, rcBlockBody(shell, scriptPath))
+	if updated != current {
+		changes = append(changes, change{path: rc, content: []byte(updated)})
+	}
+	return changes, nil
 }
 
-func makeZshCompletionFileIfNeeded(cmd *cobra.Command) {
-	if isSameZshCompletionFile(cmd) {
-		return
+// uninstallChanges returns the file operations to uninstall the completion of shell.
+func uninstallChanges(shell string, p completionPaths) ([]change, error) {
+	if err := printCompletion(shell, io.Discard); err != nil {
+		return nil, err
 	}
 
-	path := zshCompletionFilePath()
-	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
-		print.Err(fmt.Errorf("can not create zsh-completion file: %w", err))
-		return
+	changes := []change{}
+	scriptPath := p.scriptFile(shell)
+	if _, err := os.Stat(scriptPath); err == nil {
+		changes = append(changes, change{path: scriptPath, remove: true})
 	}
 
-	if err := cmd.GenZshCompletionFile(path); err != nil {
-		print.Err(fmt.Errorf("can not create zsh-completion file: %w", err))
-		return
+	rc := p.rcFile(shell)
+	if rc == "" {
+		return changes, nil
 	}
-	print.Info("create zsh-completion file: " + path)
-
-	appendFpathAtZshrcIfNeeded()
+	current, err := readFileIfExists(rc)
+	if err != nil {
+		return nil, err
+	}
+	updated := removeBlock(current)
+	if updated == "" && current != "" {
+		changes = append(changes, change{path: rc, remove: true})
+	} else if updated != current {
+		changes = append(changes, change{path: rc, content: []byte(updated)})
+	}
+	return changes, nil
 }
 
-func appendFpathAtZshrcIfNeeded() {
-	const zshFpath = XXX_ZSH_FPATH_XXX
-
-	zshrcPath := zshrcPath()
-	if !isFile(zshrcPath) {
-		fp, err := os.OpenFile(zshrcPath, os.O_RDWR|os.O_CREATE, 0664)
-		if err != nil {
-			print.Err(fmt.Errorf("can not open .zshrc: %w", err).Error())
-			return
+// applyChanges executes the file operations.
+func applyChanges(changes []change) error {
+	for _, c := range changes {
+		if c.remove {
+			if err := os.Remove(c.path); err != nil {
+				return fmt.Errorf("can not remove %s: %w", c.path, err)
+			}
+			continue
 		}
 
-		if _, err := fp.WriteString(zshFpath); err != nil {
-			print.Err(fmt.Errorf("can not write zsh $fpath in .zshrc: %w", err).Error())
-			return
+		if err := os.MkdirAll(filepath.Dir(c.path), 0775); err != nil {
+			return fmt.Errorf("can not create directory for %s: %w", c.path, err)
 		}
-
-		if err := fp.Close(); err != nil {
-			print.Err(fmt.Errorf("can not close .zshrc: %w", err).Error())
-			return
+		if err := os.WriteFile(c.path, c.content, 0664); err != nil {
+			return fmt.Errorf("can not write %s: %w", c.path, err)
 		}
-		return
 	}
+	return nil
+}
 
-	zshrc, err := os.ReadFile(zshrcPath)
-	if err != nil {
-		print.Err(fmt.Errorf("can not read .zshrc: %w", err).Error())
-		return
-	}
+// beginMarker is the first line of the block that this command manages in rc file.
+const beginMarker = "# >>> " + Name + " completion >>>"
 
-	if strings.Contains(string(zshrc), zshFpath) {
-		return
-	}
+// endMarker is the last line of the block that this command manages in rc file.
+const endMarker = "# <<< " + Name + " completion <<<"
 
-	fp, err := os.OpenFile(zshrcPath, os.O_RDWR|os.O_APPEND, 0664)
-	if err != nil {
-		print.Err(fmt.Errorf("can not open .zshrc: %w", err).Error())
-		return
-	}
+// upsertBlock replaces the marker-delimited block in content with body.
+// If content does not have the block, the block is appended.
+func upsertBlock(content, body string) string {
+	block := beginMarker + "\n" + body + endMarker + "\n"
 
-	if _, err := fp.WriteString(zshFpath); err != nil {
-		print.Err(fmt.Errorf("can not write zsh $fpath in .zshrc: %w", err).Error())
-		return
+	begin := strings.Index(content, beginMarker)
+	end := strings.Index(content, endMarker)
+	if begin == -1 || end == -1 || end < begin {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + block
 	}
-
-	if err := fp.Close(); err != nil {
-		print.Err(fmt.Errorf("can not close .zshrc: %w", err).Error())
-		return
+	end += len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
 	}
+	return content[:begin] + block + content[end:]
 }
 
-func existSameBashCompletionFile(cmd *cobra.Command) bool {
-	if !isFile(bashCompletionFilePath()) {
-		return false
+// removeBlock removes the marker-delimited block from content.
+func removeBlock(content string) string {
+	begin := strings.Index(content, beginMarker)
+	end := strings.Index(content, endMarker)
+	if begin == -1 || end == -1 || end < begin {
+		return content
 	}
-	return hasSameBashCompletionContent(cmd)
+	end += len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:begin] + content[end:]
+}
+
+// rcBlockBody returns the text in the marker-delimited block of rc file.
+func rcBlockBody(shell, scriptPath string) string {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf("[ -f %q ] && . %q\n", scriptPath, scriptPath)
+	case "zsh":
+		return fmt.Sprintf("fpath=(%q $fpath)\nautoload -Uz compinit && compinit -i\n", filepath.Dir(scriptPath))
+	case "powershell":
+		return fmt.Sprintf(". '%s'\n", strings.ReplaceAll(scriptPath, "'", "''"))
+	}
+	return ""
+}
+
+// completionPaths resolves the completion file paths. OS and home directory
+// are fields so that the path logic of every OS can be tested on any OS.
+type completionPaths struct {
+	goos string // same as runtime.GOOS
+	home string // user home directory
 }
 
-func hasSameBashCompletionContent(cmd *cobra.Command) bool {
-	bashCompletionFileInLocal, err := os.ReadFile(bashCompletionFilePath())
+// newCompletionPaths returns completionPaths for the running system.
+func newCompletionPaths() completionPaths {
+	home, err := os.UserHomeDir()
 	if err != nil {
-		print.Err(fmt.Errorf("can not read .bash_completion: %w", err).Error())
-		return false
+		home = os.Getenv("HOME")
 	}
+	return completionPaths{goos: runtime.GOOS, home: home}
+}
 
-	currentBashCompletion := new(bytes.Buffer)
-	if err := cmd.GenBashCompletion(currentBashCompletion); err != nil {
-		return false
-	}
-	if !strings.Contains(string(bashCompletionFileInLocal), currentBashCompletion.String()) {
-		return false
-	}
-	return true
+// scriptFile returns the completion script file path.
+func (p completionPaths) scriptFile(shell string) string {
+	switch shell {
+	case "bash":
+		return filepath.Join(p.home, ".local", "share", "bash-completion", "completions", Name)
+	case "fish":
+		return filepath.Join(p.home, ".config", "fish", "completions", Name+".fish")
+	case "zsh":
+		return filepath.Join(p.home, ".zsh", "completion", "_"+Name)
+	case "powershell":
+		return filepath.Join(filepath.Dir(p.powerShellProfile()), "completions", Name+".ps1")
+	}
+	return ""
 }
 
-func isSameFishCompletionFile(cmd *cobra.Command) bool {
-	path := fishCompletionFilePath()
-	if !isFile(path) {
-		return false
-	}
+// rcFile returns the file that loads the completion script.
+// It returns empty string if the shell loads the script automatically.
+func (p completionPaths) rcFile(shell string) string {
+	switch shell {
+	case "bash":
+		return filepath.Join(p.home, ".bash_completion")
+	case "zsh":
+		return filepath.Join(p.home, ".zshrc")
+	case "powershell":
+		return p.powerShellProfile()
+	}
+	return ""
+}
 
-	currentFishCompletion := new(bytes.Buffer)
-	if err := cmd.GenFishCompletion(currentFishCompletion, false); err != nil {
-		return false
+// powerShellProfile returns $PROFILE (CurrentUserCurrentHost) of PowerShell.
+// On Windows, Windows PowerShell 5.1 profile is used only when PowerShell 7
+// profile directory does not exist and Windows PowerShell one exists.
+func (p completionPaths) powerShellProfile() string {
+	const profile = "Microsoft.PowerShell_profile.ps1"
+	if p.goos != "windows" {
+		return filepath.Join(p.home, ".config", "powershell", profile)
 	}
 
-	fishCompletionInLocal, err := os.ReadFile(path)
-	if err != nil {
-		return false
+	pwsh := filepath.Join(p.home, "Documents", "PowerShell")
+	windowsPowerShell := filepath.Join(p.home, "Documents", "WindowsPowerShell")
+	if !isDir(pwsh) && isDir(windowsPowerShell) {
+		return filepath.Join(windowsPowerShell, profile)
 	}
+	return filepath.Join(pwsh, profile)
+}
 
-	if bytes.Compare(currentFishCompletion.Bytes(), fishCompletionInLocal) != 0 {
-		return false
-	}
-	return true
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
 }
 
-func isSameZshCompletionFile(cmd *cobra.Command) bool {
-	path := zshCompletionFilePath()
-	if !isFile(path) {
-		return false
+func readFileIfExists(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
 	}
-
-	currentZshCompletion := new(bytes.Buffer)
-	if err := cmd.GenZshCompletion(currentZshCompletion); err != nil {
-		return false
+	if err != nil {
+		return "", fmt.Errorf("can not read %s: %w", path, err)
 	}
+	return string(b), nil
+}
+`
+	data = strings.Replace(data, "XXX_PATH_XXX", importPath, -1)
+	return path, data
+}
 
-	zshCompletionInLocal, err := os.ReadFile(path)
-	if err != nil {
-		return false
+func completionTestFile(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join("cmd", "completion_test.go")
+	} else {
+		path = filepath.Join(name, "cmd", "completion_test.go")
 	}
+	data := `package cmd
 
-	if bytes.Compare(currentZshCompletion.Bytes(), zshCompletionInLocal) != 0 {
-		return false
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstallCompletion(t *testing.T) {
+	tests := []struct {
+		name  string
+		shell string
+		rc    string
+	}{
+		{
+			name:  "bash",
+			shell: "bash",
+			rc:    ".bash_completion",
+		},
+		{
+			name:  "fish",
+			shell: "fish",
+			rc:    "",
+		},
+		{
+			name:  "zsh",
+			shell: "zsh",
+			rc:    ".zshrc",
+		},
+		{
+			name:  "powershell",
+			shell: "powershell",
+			rc:    filepath.Join(".config", "powershell", "Microsoft.PowerShell_profile.ps1"),
+		},
 	}
-	return true
-}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := completionPaths{goos: "linux", home: t.TempDir()}
+
+			const userSetting = "# user setting\n"
+			if tt.rc != "" {
+				rc := filepath.Join(p.home, tt.rc)
+				if err := os.MkdirAll(filepath.Dir(rc), 0775); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(rc, []byte(userSetting), 0664); err != nil {
+					t.Fatal(err)
+				}
+			}
 
-// bashCompletionFilePath return bash-completion file path.
-func bashCompletionFilePath() string {
-	return filepath.Join(os.Getenv("HOME"), ".bash_completion")
-}
+			changes, err := installChanges(tt.shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := applyChanges(changes); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(p.scriptFile(tt.shell)); err != nil {
+				t.Errorf("completion script is not created: %v", err)
+			}
 
-// fishCompletionFilePath return fish-completion file path.
-func fishCompletionFilePath() string {
-	return filepath.Join(os.Getenv("HOME"), ".config", "fish", "completions", Name+".fish")
-}
+			changes, err = installChanges(tt.shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != 0 {
+				t.Errorf("install is not idempotent. changes=%v", changes)
+			}
 
-// zshCompletionFilePath return zsh-completion file path.
-func zshCompletionFilePath() string {
-	return filepath.Join(os.Getenv("HOME"), ".zsh", "completion", "_"+Name)
+			changes, err = uninstallChanges(tt.shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := applyChanges(changes); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(p.scriptFile(tt.shell)); err == nil {
+				t.Errorf("completion script is not removed")
+			}
+			if tt.rc != "" {
+				got, err := os.ReadFile(filepath.Join(p.home, tt.rc))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != userSetting {
+					t.Errorf("rc file = %q, want %q", string(got), userSetting)
+				}
+			}
+		})
+	}
 }
 
-// zshrcPath return .zshrc path.
-func zshrcPath() string {
-	return filepath.Join(os.Getenv("HOME"), ".zshrc")
-}
+func TestPowerShellProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		makeDirs []string
+		want     []string
+	}{
+		{
+			name: "linux",
+			goos: "linux",
+			want: []string{".config", "powershell", "Microsoft.PowerShell_profile.ps1"},
+		},
+		{
+			name: "windows without profile directory",
+			goos: "windows",
+			want: []string{"Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"},
+		},
+		{
+			name:     "windows with only Windows PowerShell profile directory",
+			goos:     "windows",
+			makeDirs: []string{filepath.Join("Documents", "WindowsPowerShell")},
+			want:     []string{"Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := completionPaths{goos: tt.goos, home: t.TempDir()}
+			for _, v := range tt.makeDirs {
+				if err := os.MkdirAll(filepath.Join(p.home, v), 0775); err != nil {
+					t.Fatal(err)
+				}
+			}
 
-// isFile reports whether the path exists and is a file.
-func isFile(path string) bool {
-	stat, err := os.Stat(path)
-	return (err == nil) && (!stat.IsDir())
+			want := filepath.Join(append([]string{p.home}, tt.want...)...)
+			if got := p.powerShellProfile(); got != want {
+				t.Errorf("powerShellProfile() = %s, want %s", got, want)
+			}
+		})
+	}
 }
-`
 
-	zshFpath := "`"
-	zshFpath += `
-# setting for XXX_NAME_XXX command (auto generate)
-fpath=(~/.zsh/completion $fpath)
-autoload -Uz compinit && compinit -i
+func TestPrintCompletion(t *testing.T) {
+	t.Run("unsupported shell", func(t *testing.T) {
+		err := printCompletion("no_exist_shell", &strings.Builder{})
+		if err == nil {
+			t.Errorf("printCompletion() does not return error")
+		}
+	})
+}
 `
-	zshFpath += "`"
-
-	data = strings.Replace(data, "XXX_PATH_XXX", importPath, -1)
-	data = strings.Replace(data, "XXX_NAME_XXX", name, -1)
-	zshFpath = strings.Replace(zshFpath, "XXX_NAME_XXX", name, 1)
-	data = strings.Replace(data, "XXX_ZSH_FPATH_XXX", zshFpath, 1)
-
 	return path, data
 }
 
-func versionFile(name string, noRoot bool) (string, string) {
+func versionFile(name, bin string, noRoot bool) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join("cmd", "version.go")
@@ -824,7 +1808,7 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show " + Name + " command version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(getVersion())
+		fmt.Fprintln(cmd.OutOrStdout(), getVersion())
 	},
 }
 
@@ -850,7 +1834,122 @@ func getVersion() string {
 	return fmt.Sprintf("%s version %s", Name, version)
 }
 `
-	data = strings.Replace(data, "XXX_CMD_XXX", name, -1)
+	data = strings.Replace(data, "XXX_CMD_XXX", bin, -1)
+	return path, data
+}
+
+func rootTestFile(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join("cmd", "root_test.go")
+	} else {
+		path = filepath.Join(name, "cmd", "root_test.go")
+	}
+	data := `package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRootCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "show help message",
+			args:    []string{"--help"},
+			want:    "Usage:",
+			wantErr: false,
+		},
+		{
+			name:    "unknown subcommand",
+			args:    []string{"no_exist_subcommand"},
+			want:    "unknown command",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := executeCommand(t, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				got = err.Error()
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("output does not contain %q\noutput:\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+// executeCommand executes the root command with args and returns the output.
+func executeCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs(args)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+`
+	return path, data
+}
+
+func versionTestFile(name string, noRoot bool) (string, string) {
+	var path string
+	if noRoot {
+		path = filepath.Join("cmd", "version_test.go")
+	} else {
+		path = filepath.Join(name, "cmd", "version_test.go")
+	}
+	data := `package cmd
+
+import "testing"
+
+func TestVersionCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{
+			name:    "version is set by ldflags",
+			version: "v1.2.3",
+			want:    Name + " version v1.2.3\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgVersion := Version
+			Version = tt.version
+			defer func() { Version = orgVersion }()
+
+			got, err := executeCommand(t, "version")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("version output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+`
 	return path, data
 }
 
@@ -940,7 +2039,7 @@ func Question(ask string) bool {
 	return path, data
 }
 
-func printTestFile(name string, noRoot bool) (string, string) {
+func printTestFile(name, bin string, noRoot bool) (string, string) {
 	var path string
 	if noRoot {
 		path = filepath.Join("internal", "print", "print_test.go")
@@ -1286,7 +2385,7 @@ func mockStdin(t *testing.T, dummyInput string) (funcDefer func(), err error) {
 	}, nil
 }
 `
-	data = strings.Replace(data, "XXX_CMD_NAME", name+"_", 1)
+	data = strings.Replace(data, "XXX_CMD_NAME", bin+"_", 1)
 	return path, data
 }
 
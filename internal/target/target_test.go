@@ -0,0 +1,276 @@
+package target
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nao1215/mkgoprj/v2/internal/gotool"
+)
+
+// update rewrites the golden files: $ go test ./internal/target -update
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+const (
+	testName       = "sample"
+	testImportPath = "github.com/nao1215/sample"
+	// testGoVersion is fixed so that golden files do not depend on the installed go.
+	testGoVersion = "1.22"
+)
+
+type testCase struct {
+	name   string
+	lib    bool
+	cli    bool
+	noRoot bool
+	opt    Option
+}
+
+func testCases() []testCase {
+	opt := func(f func(*Option)) Option {
+		o := Option{LintPreset: LintPresetStandard, GoVersion: testGoVersion}
+		if f != nil {
+			f(&o)
+		}
+		return o
+	}
+	return []testCase{
+		{name: "library", lib: true, opt: opt(nil)},
+		{name: "library_no_root", lib: true, noRoot: true, opt: opt(nil)},
+		{name: "library_package", lib: true, opt: opt(func(o *Option) { o.Package = "widget" })},
+		{name: "library_offline", lib: true, opt: opt(func(o *Option) { o.Offline = true })},
+		{name: "cli", cli: true, opt: opt(nil)},
+		{name: "cli_no_root", cli: true, noRoot: true, opt: opt(nil)},
+		{name: "cli_docker", cli: true, opt: opt(func(o *Option) { o.Docker = true })},
+		{name: "cli_bin_name", cli: true, opt: opt(func(o *Option) { o.BinName = "widget" })},
+		{name: "cli_offline", cli: true, opt: opt(func(o *Option) { o.Offline = true })},
+		{name: "cli_lint_minimal", cli: true, opt: opt(func(o *Option) { o.LintPreset = LintPresetMinimal })},
+		{name: "cli_lint_strict", cli: true, opt: opt(func(o *Option) { o.LintPreset = LintPresetStrict })},
+		{name: "cli_go1.20", cli: true, opt: opt(func(o *Option) { o.GoVersion = "1.20" })},
+		{name: "cli_githooks", cli: true, opt: opt(func(o *Option) { o.Hooks = HooksGit })},
+		{name: "library_pre_commit", lib: true, opt: opt(func(o *Option) { o.Hooks = HooksPreCommit })},
+		{name: "cli_devcontainer", cli: true, opt: opt(func(o *Option) { o.Devcontainer = true })},
+		{name: "library_devcontainer", lib: true, noRoot: true, opt: opt(func(o *Option) { o.Devcontainer = true })},
+	}
+}
+
+func TestFilesGolden(t *testing.T) {
+	for _, tt := range testCases() {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			files, _ := Files(testName, testImportPath, tt.lib, tt.cli, tt.noRoot, tt.opt)
+			golden := filepath.Join("testdata", "golden", tt.name)
+
+			if *update {
+				if err := os.RemoveAll(golden); err != nil {
+					t.Fatal(err)
+				}
+				writeFiles(t, golden, files)
+				return
+			}
+
+			want := readTree(t, golden)
+			for path, got := range files {
+				path = filepath.ToSlash(path)
+				w, ok := want[path]
+				if !ok {
+					t.Errorf("%s is generated, but it is not in golden files", path)
+					continue
+				}
+				if w != got {
+					t.Errorf("%s is different from golden file (run 'go test ./internal/target -update' if it is intended) (- golden + generated):\n%s",
+						path, diff(w, got))
+				}
+				delete(want, path)
+			}
+			for path := range want {
+				t.Errorf("%s is in golden files, but it is not generated", path)
+			}
+		})
+	}
+}
+
+// TestNoPlaceholder checks that all XXX_..._XXX placeholders in the templates are replaced.
+// Golden files can not find it because -update writes the placeholder as it is.
+func TestNoPlaceholder(t *testing.T) {
+	for _, tt := range testCases() {
+		files, _ := Files(testName, testImportPath, tt.lib, tt.cli, tt.noRoot, tt.opt)
+		for path, code := range files {
+			if strings.Contains(code, "XXX_") {
+				t.Errorf("%s: %s has placeholder that is not replaced", tt.name, filepath.ToSlash(path))
+			}
+		}
+	}
+}
+
+// TestGeneratedCodeCompiles builds, vets and tests the generated go code without network access.
+// The dependencies are read from the local module cache, so the test is skipped if they are not there.
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip compiling generated code in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command is not installed")
+	}
+
+	kinds := []struct {
+		name string
+		lib  bool
+		cli  bool
+	}{
+		{name: "library", lib: true},
+		{name: "cli", cli: true},
+	}
+	for _, kind := range kinds {
+		kind := kind
+		t.Run(kind.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			opt := Option{LintPreset: LintPresetStandard, GoVersion: testGoVersion, Offline: true}
+			files, _ := Files(testName, testImportPath, kind.lib, kind.cli, true, opt)
+			writeFiles(t, dir, files)
+
+			env := append(os.Environ(), gotool.OfflineEnv("")...)
+			if out, err := runGo(dir, env, "mod", "download"); err != nil {
+				t.Skipf("dependencies are not in the local module cache: %v\n%s", err, out)
+			}
+			for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}, {"test", "./..."}} {
+				if out, err := runGo(dir, env, args...); err != nil {
+					t.Fatalf("'go %v' failed: %v\n%s", args, err, out)
+				}
+			}
+		})
+	}
+}
+
+func runGo(dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	return cmd.CombinedOutput()
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, code := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree returns the files under dir: key=slash separated relative path, value=text in file.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("can not read golden files (run 'go test ./internal/target -update' to create them): %v", err)
+	}
+	return files
+}
+
+// diff returns the lines that differ between want and got.
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	var sb strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			sb.WriteString(fmt.Sprintf("line %d:\n- %s\n+ %s\n", i+1, w, g))
+		}
+	}
+	return sb.String()
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "sample", want: "sample"},
+		{name: "my-lib", want: "mylib"},
+		{name: "go-my-lib", want: "mylib"},
+		{name: "go-", want: "gopkg"},
+		{name: "go.yaml", want: "goyaml"},
+		{name: "My_Lib", want: "my_lib"},
+		{name: "9p", want: "pkg9p"},
+		{name: "type", want: "typepkg"},
+		{name: "---", want: "pkg"},
+	}
+	for _, tt := range tests {
+		if got := PackageName(tt.name); got != tt.want {
+			t.Errorf("PackageName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "sample", want: "sample"},
+		{name: "go-widget", want: "widget"},
+		{name: "my-cli", want: "my-cli"},
+		{name: "go-", want: "go-"},
+	}
+	for _, tt := range tests {
+		if got := BinaryName(tt.name); got != tt.want {
+			t.Errorf("BinaryName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilesMode(t *testing.T) {
+	executables := map[string]bool{
+		"sample/.githooks/pre-commit": true,
+		"sample/.githooks/commit-msg": true,
+	}
+	for _, tt := range testCases() {
+		files, modes := Files(testName, testImportPath, tt.lib, tt.cli, tt.noRoot, tt.opt)
+		if len(modes) != len(files) {
+			t.Errorf("%s: %d files have mode, want %d", tt.name, len(modes), len(files))
+		}
+		for path := range files {
+			want := os.FileMode(0644)
+			if executables[filepath.ToSlash(path)] {
+				want = 0755
+			}
+			if got := modes[path]; got != want {
+				t.Errorf("%s: mode of %s = %v, want %v", tt.name, filepath.ToSlash(path), got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import "testing"
+
+func TestVersionCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{
+			name:    "version is set by ldflags",
+			version: "v1.2.3",
+			want:    Name + " version v1.2.3\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgVersion := Version
+			Version = tt.version
+			defer func() { Version = orgVersion }()
+
+			got, err := executeCommand(t, "version")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("version output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
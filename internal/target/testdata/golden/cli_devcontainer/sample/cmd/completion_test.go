@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstallCompletion(t *testing.T) {
+	tests := []struct {
+		name  string
+		shell string
+		rc    string
+	}{
+		{
+			name:  "bash",
+			shell: "bash",
+			rc:    ".bash_completion",
+		},
+		{
+			name:  "fish",
+			shell: "fish",
+			rc:    "",
+		},
+		{
+			name:  "zsh",
+			shell: "zsh",
+			rc:    ".zshrc",
+		},
+		{
+			name:  "powershell",
+			shell: "powershell",
+			rc:    filepath.Join(".config", "powershell", "Microsoft.PowerShell_profile.ps1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := completionPaths{goos: "linux", home: t.TempDir()}
+
+			const userSetting = "# user setting\n"
+			if tt.rc != "" {
+				rc := filepath.Join(p.home, tt.rc)
+				if err := os.MkdirAll(filepath.Dir(rc), 0775); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(rc, []byte(userSetting), 0664); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			changes, err := installChanges(tt.shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := applyChanges(changes); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(p.scriptFile(tt.shell)); err != nil {
+				t.Errorf("completion script is not created: %v", err)
+			}
+
+			changes, err = installChanges(tt.shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != 0 {
+				t.Errorf("install is not idempotent. changes=%v", changes)
+			}
+
+			changes, err = uninstallChanges(tt.shell, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := applyChanges(changes); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(p.scriptFile(tt.shell)); err == nil {
+				t.Errorf("completion script is not removed")
+			}
+			if tt.rc != "" {
+				got, err := os.ReadFile(filepath.Join(p.home, tt.rc))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != userSetting {
+					t.Errorf("rc file = %q, want %q", string(got), userSetting)
+				}
+			}
+		})
+	}
+}
+
+func TestPowerShellProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		makeDirs []string
+		want     []string
+	}{
+		{
+			name: "linux",
+			goos: "linux",
+			want: []string{".config", "powershell", "Microsoft.PowerShell_profile.ps1"},
+		},
+		{
+			name: "windows without profile directory",
+			goos: "windows",
+			want: []string{"Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"},
+		},
+		{
+			name:     "windows with only Windows PowerShell profile directory",
+			goos:     "windows",
+			makeDirs: []string{filepath.Join("Documents", "WindowsPowerShell")},
+			want:     []string{"Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := completionPaths{goos: tt.goos, home: t.TempDir()}
+			for _, v := range tt.makeDirs {
+				if err := os.MkdirAll(filepath.Join(p.home, v), 0775); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := filepath.Join(append([]string{p.home}, tt.want...)...)
+			if got := p.powerShellProfile(); got != want {
+				t.Errorf("powerShellProfile() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestPrintCompletion(t *testing.T) {
+	t.Run("unsupported shell", func(t *testing.T) {
+		err := printCompletion("no_exist_shell", &strings.Builder{})
+		if err == nil {
+			t.Errorf("printCompletion() does not return error")
+		}
+	})
+}
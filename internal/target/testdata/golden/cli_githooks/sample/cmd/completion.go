@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/nao1215/sample/internal/print"
+	"github.com/spf13/cobra"
+)
+
+// shells is the shell names that support completion.
+var shells = []string{"bash", "fish", "zsh", "powershell"}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion",
+	Short: "Install, uninstall or print shell completion (" + strings.Join(shells, "/") + ")",
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:       "install SHELL",
+	Short:     "Install shell completion file and load it from shell rc file",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shells,
+	Run: func(cmd *cobra.Command, args []string) {
+		changes, err := installChanges(args[0], newCompletionPaths())
+		if err != nil {
+			print.Fatal(err)
+			return
+		}
+		if len(changes) == 0 {
+			print.Info(args[0] + " completion is already installed")
+			return
+		}
+		applyChangesWithConsent(cmd, changes)
+	},
+}
+
+var completionUninstallCmd = &cobra.Command{
+	Use:       "uninstall SHELL",
+	Short:     "Remove shell completion file and the setting in shell rc file",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shells,
+	Run: func(cmd *cobra.Command, args []string) {
+		changes, err := uninstallChanges(args[0], newCompletionPaths())
+		if err != nil {
+			print.Fatal(err)
+			return
+		}
+		if len(changes) == 0 {
+			print.Info(args[0] + " completion is not installed")
+			return
+		}
+		applyChangesWithConsent(cmd, changes)
+	},
+}
+
+var completionPrintCmd = &cobra.Command{
+	Use:       "print SHELL",
+	Short:     "Print shell completion script to STDOUT",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shells,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := printCompletion(args[0], cmd.OutOrStdout()); err != nil {
+			print.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{completionInstallCmd, completionUninstallCmd} {
+		c.Flags().Bool("dry-run", false, "Only show the files to be changed")
+		c.Flags().BoolP("yes", "y", false, "Change files without confirmation")
+	}
+	completionCmd.AddCommand(completionInstallCmd)
+	completionCmd.AddCommand(completionUninstallCmd)
+	completionCmd.AddCommand(completionPrintCmd)
+	rootCmd.AddCommand(completionCmd)
+}
+
+// change is the file operation to install or uninstall the shell completion.
+type change struct {
+	path    string // file path to be changed
+	content []byte // new file content. It is not used when remove is true
+	remove  bool   // whether to remove the file
+}
+
+// String returns the description of the file operation.
+func (c change) String() string {
+	if c.remove {
+		return "remove " + c.path
+	}
+	if _, err := os.Stat(c.path); err == nil {
+		return "update " + c.path
+	}
+	return "create " + c.path
+}
+
+// applyChangesWithConsent shows the file operations and executes them after user consent.
+func applyChangesWithConsent(cmd *cobra.Command, changes []change) {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		print.Fatal(err)
+		return
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		print.Fatal(err)
+		return
+	}
+
+	for _, c := range changes {
+		print.Info(c.String())
+	}
+	if dryRun {
+		return
+	}
+	if !yes && !print.Question("Do you want to change the above files?") {
+		return
+	}
+
+	if err := applyChanges(changes); err != nil {
+		print.Fatal(err)
+		return
+	}
+	print.Info("done. To activate the change, restart the shell")
+}
+
+// printCompletion writes the completion script of shell to w.
+func printCompletion(shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletion(w)
+	case "fish":
+		return rootCmd.GenFishCompletion(w, false)
+	case "zsh":
+		return rootCmd.GenZshCompletion(w)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletion(w)
+	}
+	return fmt.Errorf("unsupported shell '%s' (choose from %s)", shell, strings.Join(shells, ", "))
+}
+
+// installChanges returns the file operations to install the completion of shell.
+// Files that already have the same content are not included.
+func installChanges(shell string, p completionPaths) ([]change, error) {
+	script := new(bytes.Buffer)
+	if err := printCompletion(shell, script); err != nil {
+		return nil, err
+	}
+
+	changes := []change{}
+	scriptPath := p.scriptFile(shell)
+	if current, err := os.ReadFile(scriptPath); err != nil || !bytes.Equal(current, script.Bytes()) {
+		changes = append(changes, change{path: scriptPath, content: script.Bytes()})
+	}
+
+	rc := p.rcFile(shell)
+	if rc == "" {
+		return changes, nil
+	}
+	current, err := readFileIfExists(rc)
+	if err != nil {
+		return nil, err
+	}
+	updated := upsertBlock(current, rcBlockBody(shell, scriptPath))
+	if updated != current {
+		changes = append(changes, change{path: rc, content: []byte(updated)})
+	}
+	return changes, nil
+}
+
+// uninstallChanges returns the file operations to uninstall the completion of shell.
+func uninstallChanges(shell string, p completionPaths) ([]change, error) {
+	if err := printCompletion(shell, io.Discard); err != nil {
+		return nil, err
+	}
+
+	changes := []change{}
+	scriptPath := p.scriptFile(shell)
+	if _, err := os.Stat(scriptPath); err == nil {
+		changes = append(changes, change{path: scriptPath, remove: true})
+	}
+
+	rc := p.rcFile(shell)
+	if rc == "" {
+		return changes, nil
+	}
+	current, err := readFileIfExists(rc)
+	if err != nil {
+		return nil, err
+	}
+	updated := removeBlock(current)
+	if updated == "" && current != "" {
+		changes = append(changes, change{path: rc, remove: true})
+	} else if updated != current {
+		changes = append(changes, change{path: rc, content: []byte(updated)})
+	}
+	return changes, nil
+}
+
+// applyChanges executes the file operations.
+func applyChanges(changes []change) error {
+	for _, c := range changes {
+		if c.remove {
+			if err := os.Remove(c.path); err != nil {
+				return fmt.Errorf("can not remove %s: %w", c.path, err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(c.path), 0775); err != nil {
+			return fmt.Errorf("can not create directory for %s: %w", c.path, err)
+		}
+		if err := os.WriteFile(c.path, c.content, 0664); err != nil {
+			return fmt.Errorf("can not write %s: %w", c.path, err)
+		}
+	}
+	return nil
+}
+
+// beginMarker is the first line of the block that this command manages in rc file.
+const beginMarker = "# >>> " + Name + " completion >>>"
+
+// endMarker is the last line of the block that this command manages in rc file.
+const endMarker = "# <<< " + Name + " completion <<<"
+
+// upsertBlock replaces the marker-delimited block in content with body.
+// If content does not have the block, the block is appended.
+func upsertBlock(content, body string) string {
+	block := beginMarker + "\n" + body + endMarker + "\n"
+
+	begin := strings.Index(content, beginMarker)
+	end := strings.Index(content, endMarker)
+	if begin == -1 || end == -1 || end < begin {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + block
+	}
+	end += len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:begin] + block + content[end:]
+}
+
+// removeBlock removes the marker-delimited block from content.
+func removeBlock(content string) string {
+	begin := strings.Index(content, beginMarker)
+	end := strings.Index(content, endMarker)
+	if begin == -1 || end == -1 || end < begin {
+		return content
+	}
+	end += len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:begin] + content[end:]
+}
+
+// rcBlockBody returns the text in the marker-delimited block of rc file.
+func rcBlockBody(shell, scriptPath string) string {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf("[ -f %q ] && . %q\n", scriptPath, scriptPath)
+	case "zsh":
+		return fmt.Sprintf("fpath=(%q $fpath)\nautoload -Uz compinit && compinit -i\n", filepath.Dir(scriptPath))
+	case "powershell":
+		return fmt.Sprintf(". '%s'\n", strings.ReplaceAll(scriptPath, "'", "''"))
+	}
+	return ""
+}
+
+// completionPaths resolves the completion file paths. OS and home directory
+// are fields so that the path logic of every OS can be tested on any OS.
+type completionPaths struct {
+	goos string // same as runtime.GOOS
+	home string // user home directory
+}
+
+// newCompletionPaths returns completionPaths for the running system.
+func newCompletionPaths() completionPaths {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return completionPaths{goos: runtime.GOOS, home: home}
+}
+
+// scriptFile returns the completion script file path.
+func (p completionPaths) scriptFile(shell string) string {
+	switch shell {
+	case "bash":
+		return filepath.Join(p.home, ".local", "share", "bash-completion", "completions", Name)
+	case "fish":
+		return filepath.Join(p.home, ".config", "fish", "completions", Name+".fish")
+	case "zsh":
+		return filepath.Join(p.home, ".zsh", "completion", "_"+Name)
+	case "powershell":
+		return filepath.Join(filepath.Dir(p.powerShellProfile()), "completions", Name+".ps1")
+	}
+	return ""
+}
+
+// rcFile returns the file that loads the completion script.
+// It returns empty string if the shell loads the script automatically.
+func (p completionPaths) rcFile(shell string) string {
+	switch shell {
+	case "bash":
+		return filepath.Join(p.home, ".bash_completion")
+	case "zsh":
+		return filepath.Join(p.home, ".zshrc")
+	case "powershell":
+		return p.powerShellProfile()
+	}
+	return ""
+}
+
+// powerShellProfile returns $PROFILE (CurrentUserCurrentHost) of PowerShell.
+// On Windows, Windows PowerShell 5.1 profile is used only when PowerShell 7
+// profile directory does not exist and Windows PowerShell one exists.
+func (p completionPaths) powerShellProfile() string {
+	const profile = "Microsoft.PowerShell_profile.ps1"
+	if p.goos != "windows" {
+		return filepath.Join(p.home, ".config", "powershell", profile)
+	}
+
+	pwsh := filepath.Join(p.home, "Documents", "PowerShell")
+	windowsPowerShell := filepath.Join(p.home, "Documents", "WindowsPowerShell")
+	if !isDir(pwsh) && isDir(windowsPowerShell) {
+		return filepath.Join(windowsPowerShell, profile)
+	}
+	return filepath.Join(pwsh, profile)
+}
+
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
+}
+
+func readFileIfExists(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("can not read %s: %w", path, err)
+	}
+	return string(b), nil
+}
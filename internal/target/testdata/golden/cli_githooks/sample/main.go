@@ -0,0 +1,7 @@
+package main
+
+import "github.com/nao1215/sample/cmd"
+
+func main() {
+	cmd.Execute()
+}
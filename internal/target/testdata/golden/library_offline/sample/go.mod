@@ -0,0 +1,5 @@
+module github.com/nao1215/sample
+
+go 1.22
+
+toolchain go1.22.0
@@ -0,0 +1,5 @@
+package sample
+
+func HelloWorld() string {
+	return "Hello, World"
+}
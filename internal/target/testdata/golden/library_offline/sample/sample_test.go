@@ -0,0 +1,10 @@
+package sample
+
+import "testing"
+	
+func TestHelloWorld(t *testing.T) {
+	if HelloWorld() != "Hello, World" {
+		t.Errorf("HelloWorlf = %s, want \"Hello, World\"", HelloWorld())
+	}
+}
+	
\ No newline at end of file
@@ -0,0 +1,5 @@
+package widget
+
+func HelloWorld() string {
+	return "Hello, World"
+}